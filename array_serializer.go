@@ -87,6 +87,10 @@ func (s *ArraySerializer) Cursor(cursor Cursor) M {
 		"count":   cursor.GetCount(),
 	}
 
+	if c, ok := cursor.(LinkedCursor); ok {
+		data["links"] = cursorLinks(c)
+	}
+
 	return M{
 		"cursor": data,
 	}
@@ -125,3 +129,67 @@ func (s *ArraySerializer) InjectAvailableIncludeData(data M, availableIncludes [
 func (s *ArraySerializer) FilterIncludes(included, data Any) Any {
 	return included
 }
+
+// MandatoryFields get the fields which are kept no matter which sparse fieldset is requested
+func (s *ArraySerializer) MandatoryFields() []string {
+	return nil
+}
+
+// Links serialize the links of the resource into a flat links object
+func (s *ArraySerializer) Links(links Links) M {
+	return M{
+		"links": flatLinks(links),
+	}
+}
+
+// InjectLinks inject the links of the item into a flat links object
+func (s *ArraySerializer) InjectLinks(data M, links Links) M {
+	if len(links) == 0 {
+		return data
+	}
+
+	data["links"] = mergeMaps(toM(data["links"]), flatLinks(links))
+	return data
+}
+
+// Render the links as a map of their relations to their hrefs
+func flatLinks(links Links) M {
+	flat := M{}
+
+	for rel, link := range links {
+		flat[rel] = link.Href
+	}
+
+	return flat
+}
+
+// Convert the links rendered by the serializers into M
+func toM(data Any) M {
+	switch d := data.(type) {
+	case M:
+		return d
+	case map[string]string:
+		m := M{}
+		for k, v := range d {
+			m[k] = v
+		}
+		return m
+	}
+
+	return nil
+}
+
+// Build the links to the previous and next position of the cursor
+func cursorLinks(cursor LinkedCursor) map[string]string {
+	links := map[string]string{}
+
+	if prev := cursor.GetPrev(); prev != "" {
+		links["prev"] = cursor.GetURL(prev)
+	}
+
+	if next := cursor.GetNext(); next != "" {
+		links["next"] = cursor.GetURL(next)
+	}
+
+	return links
+}
@@ -0,0 +1,25 @@
+package fractal
+
+// Authorization the decision of an authorizer
+type Authorization int
+
+const (
+	// AuthorizationAllow keeps the include or the field
+	AuthorizationAllow Authorization = iota
+	// AuthorizationDrop drops the include or the field silently
+	AuthorizationDrop
+	// AuthorizationReject fails the render with a ForbiddenError, a rejected include
+	// which is not requested by name, e.g. a default include or one requested by
+	// a wildcard, is dropped silently instead.
+	AuthorizationReject
+)
+
+// IncludeAuthorizer decides if the principal of the scope, see Scope.GetPrincipal, is allowed
+// to see the include, path is the full include path, e.g. "user.email". It is called before
+// the include is included, and may be called concurrently if SetConcurrency is set.
+type IncludeAuthorizer func(scope *Scope, path string) Authorization
+
+// FieldAuthorizer decides if the principal of the scope is allowed to see the field of the
+// transformed data, path is the full path of the field, e.g. "user.email". It is called after
+// the data is transformed, and may be called concurrently if SetConcurrency is set.
+type FieldAuthorizer func(scope *Scope, path string, data Any) Authorization
@@ -9,7 +9,14 @@ func (t *closureTransformer) Transform(data Any) M {
 	return t.trans(t.BaseTransformer, data)
 }
 
-// T is a wrapper for closure transformer
+// TransformWithContext perform transform, the closure gets a copy of the transformer
+// set to the scope of the context, so a closure transformer can be shared.
+func (t *closureTransformer) TransformWithContext(ctx *TransformContext, data Any) (M, error) {
+	return t.trans(t.withScope(ctx), data), nil
+}
+
+// T is a wrapper for closure transformer, the transformer passed to the closure is
+// set to the scope of the item, so the closure transformer can be shared.
 func T(trans func(t *BaseTransformer, data Any) M) Transformer {
 	return &closureTransformer{
 		BaseTransformer: &BaseTransformer{}, trans: trans,
@@ -5,6 +5,7 @@ type Collection struct {
 	Item
 	paginator Paginator
 	cursor    Cursor
+	mapOrder  MapOrder
 }
 
 // GetPaginator get the paginator instance
@@ -39,7 +40,19 @@ func (c *Collection) SetCursor(cursor Cursor) *Collection {
 	return c
 }
 
-// NewCollection create new collection resource
+// GetMapOrder get the order in which the values of a map data are used as items
+func (c *Collection) GetMapOrder() MapOrder {
+	return c.mapOrder
+}
+
+// SetMapOrder set the order in which the values of a map data are used as items
+func (c *Collection) SetMapOrder(order MapOrder) *Collection {
+	c.mapOrder = order
+	return c
+}
+
+// NewCollection create new collection resource, the data can be a slice,
+// an array, a map or an Iterator of any sort of data
 func NewCollection(opts ...ModResourceOption) *Collection {
 	opt := &ResourceOption{}
 
@@ -51,7 +64,9 @@ func NewCollection(opts ...ModResourceOption) *Collection {
 		Item: Item{
 			data:        opt.data,
 			resourceKey: opt.resourceKey,
+			links:       opt.links,
 			transformer: opt.transformer,
 		},
+		mapOrder: opt.mapOrder,
 	}
 }
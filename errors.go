@@ -0,0 +1,81 @@
+package fractal
+
+import (
+	"errors"
+	"strings"
+)
+
+// IncludeSyntaxError is returned when a requested include can not be parsed
+type IncludeSyntaxError struct {
+	Include string
+	Reason  string
+}
+
+func (e *IncludeSyntaxError) Error() string {
+	return "malformed include \"" + e.Include + "\": " + e.Reason
+}
+
+// UnknownIncludesError is returned in strict mode when includes are requested but
+// offered by no transformer, Suggestions are the offered includes close to the unknown
+// ones by the unknown include paths.
+type UnknownIncludesError struct {
+	Paths       []string
+	Suggestions map[string][]string
+}
+
+func (e *UnknownIncludesError) Error() string {
+	parts := make([]string, len(e.Paths))
+
+	for i, path := range e.Paths {
+		parts[i] = "\"" + path + "\""
+		if suggestions := e.Suggestions[path]; len(suggestions) > 0 {
+			parts[i] += " (did you mean \"" + strings.Join(suggestions, "\" or \"") + "\"?)"
+		}
+	}
+
+	return "unknown includes " + strings.Join(parts, ", ")
+}
+
+// ForbiddenError is returned when an authorizer rejects an include or a field,
+// Path is the full path of the include or the field, e.g. "user.email"
+type ForbiddenError struct {
+	Path string
+}
+
+func (e *ForbiddenError) Error() string {
+	return "access to \"" + e.Path + "\" is forbidden"
+}
+
+// ScopeError is returned when the data of a scope can not be transformed,
+// Identifier is the full identifier of the failed scope, e.g. "book.category.creator"
+type ScopeError struct {
+	Identifier string
+	Err        error
+}
+
+func (e *ScopeError) Error() string {
+	if e.Identifier == "" {
+		return e.Err.Error()
+	}
+	return e.Identifier + ": " + e.Err.Error()
+}
+
+// Unwrap get the underlying error
+func (e *ScopeError) Unwrap() error {
+	return e.Err
+}
+
+// Wrap the error with the identifier of the scope where it happens,
+// errors already wrapped by a nested scope are returned as they are.
+func wrapScopeError(identifier string, err error) error {
+	if err == nil {
+		return nil
+	}
+
+	var scopeErr *ScopeError
+	if errors.As(err, &scopeErr) {
+		return err
+	}
+
+	return &ScopeError{Identifier: identifier, Err: err}
+}
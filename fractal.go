@@ -3,11 +3,11 @@ package fractal
 // DefaultResourceKey default resource key
 const DefaultResourceKey = "data"
 
+// WildcardInclude requests all the available includes at its level, e.g. "*" or "author.*"
+const WildcardInclude = "*"
+
 // M is a shortcut for map[string]interface{}
 type M = map[string]interface{}
 
-// P is a shortcut for map[string]string
-type P = map[string]string
-
 // Any is a shortcut for interface{}
 type Any = interface{}
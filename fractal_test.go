@@ -1,7 +1,14 @@
 package fractal_test
 
 import (
+	"bytes"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
 	"testing"
 
 	"github.com/ibllex/go-fractal"
@@ -29,6 +36,14 @@ func (t *UserTransformer) Transform(data fractal.Any) fractal.M {
 	return result
 }
 
+func (t *UserTransformer) TransformWithError(data fractal.Any) (fractal.M, error) {
+	if u := t.toUser(data); u != nil && u.ID < 0 {
+		return nil, fmt.Errorf("invalid user id %d", u.ID)
+	}
+
+	return t.Transform(data), nil
+}
+
 func (t *UserTransformer) toUser(data fractal.Any) *User {
 
 	switch u := data.(type) {
@@ -107,8 +122,13 @@ func (t *CategoryTransformer) includeBooks(data fractal.Any, params fractal.P) f
 			books[i] = c.Books[i]
 		}
 
+		if limit, err := strconv.Atoi(params.First("limit")); err == nil && limit < len(books) {
+			books = books[:limit]
+		}
+
 		opts := []fractal.ModResourceOption{
 			fractal.WithData(books),
+			fractal.WithResourceKey("books"),
 			fractal.WithTransformer(NewBookTransformer()),
 		}
 
@@ -125,6 +145,7 @@ func (t *CategoryTransformer) includeUser(data fractal.Any, params fractal.P) fr
 
 			opts := []fractal.ModResourceOption{
 				fractal.WithData(c.Creator),
+				fractal.WithResourceKey("users"),
 				fractal.WithTransformer(NewUserTransformer()),
 			}
 
@@ -204,6 +225,7 @@ func (t *BookTransformer) includeCategory(data fractal.Any, params fractal.P) fr
 		if b.Category != nil {
 			opts := []fractal.ModResourceOption{
 				fractal.WithData(b.Category),
+				fractal.WithResourceKey("categories"),
 				fractal.WithTransformer(
 					NewCategoryTransformer().SetPrimitive(t.primitive),
 				),
@@ -468,3 +490,1561 @@ func TestPrimitiveCollectionInclude(t *testing.T) {
 	assert.Nil(t, err)
 	assert.Equal(t, expected, actual)
 }
+
+func TestIncludeParams(t *testing.T) {
+
+	books := []*Book{
+		{1, "Hogfather", 1998, "Philip K Dick", &Category{}},
+		{2, "Game Of Kill Everyone", 2014, "George R. R. Satan", &Category{}},
+	}
+
+	cat := &Category{ID: 1, Name: "novel", Books: books}
+
+	manager := fractal.NewManager(nil)
+	err := manager.ParseIncludesE([]string{"books:limit(1)"})
+	assert.Nil(t, err)
+
+	resource := fractal.NewItem(
+		fractal.WithData(cat),
+		fractal.WithTransformer(NewCategoryTransformer()),
+	)
+
+	expected := fractal.M{"data": fractal.M{
+		"id":   cat.ID,
+		"name": cat.Name,
+		"books": []fractal.Any{
+			map[string]interface{}{
+				"id":     books[0].ID,
+				"title":  "'" + books[0].Title + "'",
+				"year":   books[0].Year,
+				"author": books[0].Author,
+			},
+		},
+	}}
+
+	actual, err := manager.CreateData(resource, nil).ToMap()
+
+	assert.Nil(t, err)
+	assert.Equal(t, expected, actual)
+}
+
+func TestExclude(t *testing.T) {
+	cat := &Category{ID: 1, Name: "novel", Creator: &User{ID: 1, Name: "Tamas"}}
+	book := Book{1, "Hogfather", 1998, "Philip K Dick", cat}
+
+	transformer := NewBookTransformer()
+	transformer.SetDefaultIncludes([]string{"category"})
+
+	resource := fractal.NewItem(
+		fractal.WithData(book),
+		fractal.WithTransformer(transformer),
+	)
+
+	t.Run("default include", func(t *testing.T) {
+		manager := fractal.NewManager(nil)
+		manager.ParseExcludes([]string{"category"})
+
+		expected := fractal.M{"data": fractal.M{
+			"id":     book.ID,
+			"title":  "'" + book.Title + "'",
+			"year":   book.Year,
+			"author": book.Author,
+		}}
+
+		actual, err := manager.CreateData(resource, nil).ToMap()
+
+		assert.Nil(t, err)
+		assert.Equal(t, expected, actual)
+		assert.Equal(t, []string{"category"}, transformer.GetDefaultIncludes())
+	})
+
+	t.Run("nested include", func(t *testing.T) {
+		manager := fractal.NewManager(nil)
+		manager.ParseIncludes([]string{"category.creator"})
+		manager.ParseExcludes([]string{"category.creator"})
+
+		expected := fractal.M{"data": fractal.M{
+			"id":     book.ID,
+			"title":  "'" + book.Title + "'",
+			"year":   book.Year,
+			"author": book.Author,
+			"category": fractal.M{
+				"data": fractal.M{
+					"id":   cat.ID,
+					"name": cat.Name,
+				},
+			},
+		}}
+
+		actual, err := manager.CreateData(resource, nil).ToMap()
+
+		assert.Nil(t, err)
+		assert.Equal(t, expected, actual)
+	})
+}
+
+func TestFieldsets(t *testing.T) {
+	cat := &Category{ID: 1, Name: "novel", Creator: &User{ID: 1, Name: "Tamas"}}
+	books := []fractal.Any{
+		Book{1, "Hogfather", 1998, "Philip K Dick", cat},
+		Book{2, "Game Of Kill Everyone", 2014, "George R. R. Satan", cat},
+	}
+
+	resource := fractal.NewCollection(
+		fractal.WithData(books),
+		fractal.WithResourceKey("books"),
+		fractal.WithTransformer(NewBookTransformer()),
+	)
+
+	t.Run("fields", func(t *testing.T) {
+		manager := fractal.NewManager(nil)
+		manager.ParseIncludes([]string{"category"})
+		manager.ParseFieldsets(map[string]string{"books": "title,year"})
+
+		expected := fractal.M{"data": []fractal.Any{
+			fractal.M{"title": "'Hogfather'", "year": 1998},
+			fractal.M{"title": "'Game Of Kill Everyone'", "year": 2014},
+		}}
+
+		actual, err := manager.CreateData(resource, nil).ToMap()
+
+		assert.Nil(t, err)
+		assert.Equal(t, expected, actual)
+	})
+
+	t.Run("nested include", func(t *testing.T) {
+		manager := fractal.NewManager(nil)
+		manager.ParseIncludes([]string{"category"})
+		manager.ParseFieldsets(map[string]string{"books": "title,category"})
+
+		category := fractal.M{"data": fractal.M{"id": cat.ID, "name": cat.Name}}
+		expected := fractal.M{"data": []fractal.Any{
+			fractal.M{"title": "'Hogfather'", "category": category},
+			fractal.M{"title": "'Game Of Kill Everyone'", "category": category},
+		}}
+
+		actual, err := manager.CreateData(resource, nil).ToMap()
+
+		assert.Nil(t, err)
+		assert.Equal(t, expected, actual)
+	})
+
+	t.Run("empty fieldset", func(t *testing.T) {
+		manager := fractal.NewManager(nil)
+		manager.ParseIncludes([]string{"category"})
+		manager.ParseFieldsets(map[string]string{"books": ""})
+
+		actual, err := manager.CreateData(resource, nil).ToMap()
+
+		assert.Nil(t, err)
+		assert.Equal(t, fractal.M{"data": []fractal.Any{fractal.M{}, fractal.M{}}}, actual)
+
+		manager.SetSerializer(fractal.NewJsonApiSerializer(""))
+
+		actual, err = manager.CreateData(resource, nil).ToMap()
+
+		assert.Nil(t, err)
+		assert.Equal(t, []fractal.Any{
+			fractal.M{"type": "books", "id": "1", "attributes": fractal.M{}},
+			fractal.M{"type": "books", "id": "2", "attributes": fractal.M{}},
+		}, actual["data"])
+	})
+}
+
+// SideloadSerializer puts the includes of every item into the "included" map,
+// keyed by the include name, and records the names on the item itself.
+type SideloadSerializer struct {
+	fractal.DataArraySerializer
+	filtered bool
+}
+
+func (s *SideloadSerializer) SideloadIncludes() bool {
+	return true
+}
+
+func (s *SideloadSerializer) IncludeData(resource fractal.Resource, data fractal.Any) fractal.Any {
+	included := fractal.M{}
+
+	for _, includes := range data.([]fractal.M) {
+		for name, include := range includes {
+			list, _ := included[name].([]fractal.Any)
+			included[name] = append(list, include)
+		}
+	}
+
+	if len(included) == 0 {
+		return nil
+	}
+
+	return fractal.M{"included": included}
+}
+
+func (s *SideloadSerializer) InjectData(data, rawIncluded fractal.Any) fractal.Any {
+	document := data.(fractal.M)
+	items, ok := document["data"].([]fractal.Any)
+	if !ok {
+		return document
+	}
+
+	for i, item := range items {
+		relations := []string{}
+		for name := range rawIncluded.([]fractal.M)[i] {
+			relations = append(relations, name)
+		}
+		item.(fractal.M)["relations"] = relations
+	}
+
+	return document
+}
+
+func (s *SideloadSerializer) FilterIncludes(included, data fractal.Any) fractal.Any {
+	s.filtered = true
+	return included
+}
+
+func TestSideloadIncludes(t *testing.T) {
+	cat := &Category{ID: 1, Name: "novel"}
+	books := []fractal.Any{
+		Book{1, "Hogfather", 1998, "Philip K Dick", cat},
+		Book{2, "Game Of Kill Everyone", 2014, "George R. R. Satan", cat},
+	}
+
+	serializer := &SideloadSerializer{}
+	manager := fractal.NewManager(nil)
+	manager.SetSerializer(serializer)
+	manager.ParseIncludes([]string{"category"})
+
+	resource := fractal.NewCollection(
+		fractal.WithData(books),
+		fractal.WithTransformer(NewBookTransformer()),
+	)
+
+	category := fractal.M{"data": fractal.M{"id": cat.ID, "name": cat.Name}}
+	expected := fractal.M{
+		"data": []fractal.Any{
+			fractal.M{
+				"id":        1,
+				"title":     "'Hogfather'",
+				"year":      1998,
+				"author":    "Philip K Dick",
+				"relations": []string{"category"},
+			},
+			fractal.M{
+				"id":        2,
+				"title":     "'Game Of Kill Everyone'",
+				"year":      2014,
+				"author":    "George R. R. Satan",
+				"relations": []string{"category"},
+			},
+		},
+		"included": fractal.M{
+			"category": []fractal.Any{category, category},
+		},
+	}
+
+	actual, err := manager.CreateData(resource, nil).ToMap()
+
+	assert.Nil(t, err)
+	assert.Equal(t, expected, actual)
+	assert.True(t, serializer.filtered)
+}
+
+func TestCursor(t *testing.T) {
+	books := []fractal.Any{
+		Book{1, "Hogfather", 1998, "Philip K Dick", &Category{}},
+		Book{2, "Game Of Kill Everyone", 2014, "George R. R. Satan", &Category{}},
+	}
+
+	current, _ := pagination.EncodeKeyset(pagination.Keyset{"id": 0})
+	cursor := pagination.NewKeysetCursor(
+		books, current,
+		pagination.WithNextKeys(pagination.Keyset{"id": 2}),
+		pagination.WithCursorPath("https://www.example.com/books/"),
+		pagination.WithCursorQuery(map[string]string{"cat": "1"}),
+	)
+
+	manager := fractal.NewManager(nil)
+	resource := fractal.NewCollection(
+		fractal.WithData(books),
+		fractal.WithTransformer(NewBookTransformer()),
+	).SetCursor(cursor)
+
+	actual, err := manager.CreateData(resource, nil).ToMap()
+	assert.Nil(t, err)
+
+	next := cursor.GetNext()
+	expected := fractal.M{
+		"current": current,
+		"prev":    "",
+		"next":    next,
+		"count":   uint(2),
+		"links": map[string]string{
+			"next": "https://www.example.com/books?cat=1&cursor=" + next,
+		},
+	}
+
+	assert.Equal(t, fractal.M{"cursor": expected}, actual["meta"])
+
+	keys, err := pagination.DecodeKeyset(next)
+	assert.Nil(t, err)
+	assert.Equal(t, pagination.Keyset{"id": json.Number("2")}, keys)
+
+	_, err = pagination.DecodeKeyset("not a cursor")
+	assert.NotNil(t, err)
+}
+
+type BrokenIncludeTransformer struct {
+	fractal.BaseTransformer
+}
+
+func (t *BrokenIncludeTransformer) IncludeWithError(includeName string, data fractal.Any, params fractal.P) (fractal.Resource, error) {
+
+	switch includeName {
+	case "failed":
+		return nil, errors.New("include failed")
+	case "malformed":
+		return t.Collection(fractal.WithData("books")), nil
+	}
+
+	return nil, nil
+}
+
+func NewBrokenIncludeTransformer() *BrokenIncludeTransformer {
+	t := &BrokenIncludeTransformer{}
+	t.SetErrorIncluder(t).SetAvailableIncludes([]string{"failed", "malformed"})
+	return t
+}
+
+func TestErrors(t *testing.T) {
+	manager := fractal.NewManager(nil)
+
+	t.Run("nested transformer", func(t *testing.T) {
+		cat := &Category{ID: 1, Name: "novel", Creator: &User{ID: -1, Name: "Tamas"}}
+		book := Book{1, "Hogfather", 1998, "Philip K Dick", cat}
+
+		manager.ParseIncludes([]string{"category.creator"})
+		resource := fractal.NewItem(
+			fractal.WithData(book),
+			fractal.WithTransformer(NewBookTransformer()),
+		)
+
+		actual, err := manager.CreateData(resource, nil, fractal.WithIdentifier("book")).ToJSON()
+
+		var scopeErr *fractal.ScopeError
+		assert.Equal(t, "", actual)
+		assert.True(t, errors.As(err, &scopeErr))
+		assert.Equal(t, "book.category.creator", scopeErr.Identifier)
+		assert.EqualError(t, err, "book.category.creator: invalid user id -1")
+	})
+
+	t.Run("includer", func(t *testing.T) {
+		manager.ParseIncludes([]string{"failed"})
+		resource := fractal.NewCollection(
+			fractal.WithData([]fractal.Any{fractal.M{}}),
+			fractal.WithTransformer(NewBrokenIncludeTransformer()),
+		)
+
+		_, err := manager.CreateData(resource, nil).ToMap()
+		assert.EqualError(t, err, "failed: include failed")
+	})
+
+	t.Run("malformed nested collection", func(t *testing.T) {
+		manager.ParseIncludes([]string{"malformed"})
+		resource := fractal.NewItem(
+			fractal.WithData(fractal.M{}),
+			fractal.WithTransformer(NewBrokenIncludeTransformer()),
+		)
+
+		_, err := manager.CreateData(resource, nil, fractal.WithIdentifier("root")).ToMap()
+		assert.EqualError(t, err, "root.malformed: the data of collection resource should be a slice, an array, a map or a fractal.Iterator")
+	})
+}
+
+type bookIterator struct {
+	books []Book
+	book  Book
+}
+
+func (it *bookIterator) Next() bool {
+	if len(it.books) == 0 {
+		return false
+	}
+	it.book, it.books = it.books[0], it.books[1:]
+	return true
+}
+
+func (it *bookIterator) Value() fractal.Any {
+	return it.book
+}
+
+func (it *bookIterator) Err() error {
+	return nil
+}
+
+func TestCollectionData(t *testing.T) {
+	hogfather := Book{1, "Hogfather", 1998, "Philip K Dick", &Category{}}
+	kill := Book{2, "Game Of Kill Everyone", 2014, "George R. R. Satan", &Category{}}
+
+	transformer := fractal.T(func(t *fractal.BaseTransformer, data fractal.Any) fractal.M {
+		return fractal.M{"id": data.(Book).ID}
+	})
+
+	manager := fractal.NewManager(nil)
+	ascending := fractal.M{"data": []fractal.Any{fractal.M{"id": 1}, fractal.M{"id": 2}}}
+	descending := fractal.M{"data": []fractal.Any{fractal.M{"id": 2}, fractal.M{"id": 1}}}
+
+	for name, c := range map[string]struct {
+		data     fractal.Any
+		opts     []fractal.ModResourceOption
+		expected fractal.M
+	}{
+		"slice":          {[]Book{hogfather, kill}, nil, ascending},
+		"array":          {[2]Book{hogfather, kill}, nil, ascending},
+		"array pointer":  {&[2]Book{hogfather, kill}, nil, ascending},
+		"map":            {map[int]Book{2: kill, 1: hogfather}, nil, ascending},
+		"map descending": {map[string]Book{"b": kill, "a": hogfather}, []fractal.ModResourceOption{fractal.WithMapOrder(fractal.MapOrderDesc)}, descending},
+		"iterator":       {&bookIterator{books: []Book{hogfather, kill}}, nil, ascending},
+		"empty":          {nil, nil, fractal.M{"data": []fractal.Any{}}},
+	} {
+		t.Run(name, func(t *testing.T) {
+			opts := append(c.opts, fractal.WithData(c.data), fractal.WithTransformer(transformer))
+			actual, err := manager.CreateData(fractal.NewCollection(opts...), nil).ToMap()
+
+			assert.Nil(t, err)
+			assert.Equal(t, c.expected, actual)
+		})
+	}
+
+	t.Run("primitive collection", func(t *testing.T) {
+		resource := fractal.NewPrimitiveCollection(
+			fractal.WithData(map[int]Book{2: kill, 1: hogfather}),
+			fractal.WithTransformer(transformer),
+		)
+
+		actual, err := manager.CreateData(resource, nil).TransformPrimitiveResource()
+
+		assert.Nil(t, err)
+		assert.Equal(t, ascending["data"], actual)
+	})
+}
+
+type LinkedBookTransformer struct {
+	*BookTransformer
+}
+
+func (t *LinkedBookTransformer) Links(data fractal.Any) fractal.Links {
+	return fractal.Links{
+		"self": {Href: fmt.Sprintf("/books/%d", t.toBook(data).ID)},
+	}
+}
+
+func TestLinks(t *testing.T) {
+	books := []fractal.Any{
+		&Book{ID: 1, Title: "Hogfather"},
+		&Book{ID: 2, Title: "Game Of Kill Everyone"},
+	}
+
+	newResource := func() *fractal.Collection {
+		resource := fractal.NewCollection(
+			fractal.WithData(books),
+			fractal.WithResourceKey("books"),
+			fractal.WithTransformer(&LinkedBookTransformer{NewBookTransformer()}),
+		)
+		resource.AddLink("self", "/books", nil)
+		resource.AddLink("search", "/books{?q}", fractal.M{"templated": true})
+		return resource
+	}
+
+	t.Run("array serializer", func(t *testing.T) {
+		manager := fractal.NewManager(nil)
+		manager.ParseFieldsets(map[string]string{"books": "id"})
+
+		expected := fractal.M{
+			"data": []fractal.Any{
+				fractal.M{"id": 1, "links": fractal.M{"self": "/books/1"}},
+				fractal.M{"id": 2, "links": fractal.M{"self": "/books/2"}},
+			},
+			"links": fractal.M{"self": "/books", "search": "/books{?q}"},
+		}
+
+		actual, err := manager.CreateData(newResource(), nil).ToMap()
+
+		assert.Nil(t, err)
+		assert.Equal(t, expected, actual)
+	})
+
+	t.Run("json api serializer", func(t *testing.T) {
+		manager := fractal.NewManager(nil)
+		manager.SetSerializer(fractal.NewJsonApiSerializer(""))
+		manager.ParseFieldsets(map[string]string{"books": "id"})
+
+		expected := fractal.M{
+			"data": []fractal.Any{
+				fractal.M{"type": "books", "id": "1", "attributes": fractal.M{}, "links": fractal.M{"self": "/books/1"}},
+				fractal.M{"type": "books", "id": "2", "attributes": fractal.M{}, "links": fractal.M{"self": "/books/2"}},
+			},
+			"links": fractal.M{
+				"self":   "/books",
+				"search": fractal.M{"href": "/books{?q}", "meta": fractal.M{"templated": true}},
+			},
+		}
+
+		actual, err := manager.CreateData(newResource(), nil).ToMap()
+
+		assert.Nil(t, err)
+		assert.Equal(t, expected, actual)
+	})
+
+	t.Run("hal serializer", func(t *testing.T) {
+		manager := fractal.NewManager(nil)
+		manager.SetSerializer(&fractal.HalSerializer{})
+		manager.ParseFieldsets(map[string]string{"books": "id"})
+
+		resource := fractal.NewItem(
+			fractal.WithData(books[0]),
+			fractal.WithResourceKey("books"),
+			fractal.WithTransformer(&LinkedBookTransformer{NewBookTransformer()}),
+			fractal.WithLinks(fractal.Links{"collection": {Href: "/books"}}),
+		)
+
+		expected := fractal.M{
+			"id": 1,
+			"_links": fractal.M{
+				"self":       fractal.M{"href": "/books/1"},
+				"collection": fractal.M{"href": "/books"},
+			},
+		}
+
+		actual, err := manager.CreateData(resource, nil).ToMap()
+
+		assert.Nil(t, err)
+		assert.Equal(t, expected, actual)
+	})
+}
+
+type bookRows struct {
+	books  []*Book
+	index  int
+	closed bool
+}
+
+func (r *bookRows) Next() bool {
+	r.index++
+	return r.index <= len(r.books)
+}
+
+func (r *bookRows) Err() error {
+	return nil
+}
+
+func (r *bookRows) Close() error {
+	r.closed = true
+	return nil
+}
+
+func TestPrimitiveCollectionRows(t *testing.T) {
+	users := []*User{{ID: 1}, {ID: -1}, {ID: 3}}
+	rows := &bookRows{books: make([]*Book, len(users))}
+
+	items := fractal.FromRows(rows, func() (fractal.Any, error) {
+		return users[rows.index-1], nil
+	})
+
+	resource := fractal.NewPrimitiveCollection(fractal.WithData(items), fractal.WithTransformer(NewUserTransformer()))
+	_, err := fractal.NewManager(nil).CreateData(resource, nil).TransformPrimitiveResource()
+
+	assert.EqualError(t, err, "invalid user id -1")
+	assert.True(t, rows.closed)
+}
+
+func TestWriteJSON(t *testing.T) {
+	cat := &Category{ID: 1, Name: "novel"}
+	books := []*Book{
+		{1, "Hogfather", 1998, "Philip K Dick", cat},
+		{2, "Game Of Kill Everyone", 2014, "George R. R. Satan", cat},
+		{3, "-1", 2021, "Broken", cat},
+	}
+
+	newResource := func(rows *bookRows) *fractal.Collection {
+		items := fractal.FromRows(rows, func() (fractal.Any, error) {
+			return rows.books[rows.index-1], nil
+		})
+
+		page := pagination.NewLengthAwarePaginator(nil, 4, 2, pagination.WithPath("/books"))
+
+		return fractal.NewCollection(
+			fractal.WithData(items),
+			fractal.WithResourceKey("books"),
+			fractal.WithTransformer(NewBookTransformer()),
+		).SetPaginator(page)
+	}
+
+	for name, serializer := range map[string]fractal.Serializer{
+		"data array serializer": &fractal.DataArraySerializer{},
+		"hal serializer":        &fractal.HalSerializer{},
+		"json api serializer":   fractal.NewJsonApiSerializer("/"),
+	} {
+		t.Run(name, func(t *testing.T) {
+			manager := fractal.NewManager(nil)
+			manager.SetSerializer(serializer)
+			manager.ParseIncludes([]string{"category"})
+
+			expected, err := manager.CreateData(newResource(&bookRows{books: books[:2]}), nil).ToJSON()
+			assert.Nil(t, err)
+
+			rows := &bookRows{books: books[:2]}
+			buf := &bytes.Buffer{}
+
+			assert.Nil(t, manager.CreateData(newResource(rows), nil).WriteJSON(buf))
+			assert.Equal(t, expected, buf.String())
+			assert.True(t, rows.closed)
+		})
+	}
+
+	t.Run("item", func(t *testing.T) {
+		manager := fractal.NewManager(nil)
+		resource := fractal.NewItem(fractal.WithData(books[0]), fractal.WithTransformer(NewBookTransformer()))
+		buf := &bytes.Buffer{}
+
+		assert.Nil(t, manager.CreateData(resource, nil).WriteJSON(buf))
+		assert.Equal(t, `{"data":{"author":"Philip K Dick","id":1,"title":"'Hogfather'","year":1998}}`, buf.String())
+	})
+
+	t.Run("failed item", func(t *testing.T) {
+		manager := fractal.NewManager(nil)
+		rows := &bookRows{books: books}
+		transformer := fractal.T(func(t *fractal.BaseTransformer, data fractal.Any) fractal.M {
+			return fractal.M{"id": data.(*Book).ID}
+		})
+		items := fractal.FromRows(rows, func() (fractal.Any, error) {
+			if b := rows.books[rows.index-1]; b.Title != "-1" {
+				return b, nil
+			}
+			return nil, errors.New("broken row")
+		})
+		buf := &bytes.Buffer{}
+
+		err := manager.CreateData(fractal.NewCollection(fractal.WithData(items), fractal.WithTransformer(transformer)), nil).WriteJSON(buf)
+
+		assert.EqualError(t, err, "broken row")
+		assert.Equal(t, `{"data":[{"id":1},{"id":2}`, buf.String())
+		assert.True(t, rows.closed)
+	})
+}
+
+func TestWriteNDJSON(t *testing.T) {
+	cat := &Category{ID: 1, Name: "novel"}
+	books := []*Book{
+		{1, "Hogfather", 1998, "Philip K Dick", cat},
+		{2, "Game Of Kill Everyone", 2014, "George R. R. Satan", cat},
+	}
+
+	newResource := func() *fractal.Collection {
+		page := pagination.NewLengthAwarePaginator(nil, 2, 2, pagination.WithPath("/books"))
+
+		return fractal.NewCollection(
+			fractal.WithData(books),
+			fractal.WithResourceKey("books"),
+			fractal.WithTransformer(NewBookTransformer()),
+		).SetPaginator(page)
+	}
+
+	manager := fractal.NewManager(nil)
+	manager.ParseIncludes([]string{"category"})
+	manager.ParseFieldsets(map[string]string{"books": "id,category"})
+
+	t.Run("collection", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+
+		assert.Nil(t, manager.CreateData(newResource(), nil).WriteNDJSON(buf, false))
+		assert.Equal(t, ""+
+			`{"category":{"data":{"id":1,"name":"novel"}},"id":1}`+"\n"+
+			`{"category":{"data":{"id":1,"name":"novel"}},"id":2}`+"\n",
+			buf.String(),
+		)
+	})
+
+	t.Run("with meta", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+
+		assert.Nil(t, manager.CreateData(newResource(), nil).WriteNDJSON(buf, true))
+
+		lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+		assert.Len(t, lines, 3)
+		assert.Equal(t, `{"meta":{"pagination":{"count":0,"current_page":1,"links":{},"per_page":2,"total":2,"total_pages":1}}}`, lines[2])
+	})
+
+	t.Run("side-loading serializer", func(t *testing.T) {
+		manager := fractal.NewManager(nil)
+		manager.SetSerializer(fractal.NewJsonApiSerializer(""))
+		manager.ParseIncludes([]string{"category"})
+		buf := &bytes.Buffer{}
+
+		resource := fractal.NewItem(
+			fractal.WithData(books[0]),
+			fractal.WithResourceKey("books"),
+			fractal.WithTransformer(NewBookTransformer()),
+		)
+
+		assert.Nil(t, manager.CreateData(resource, nil).WriteNDJSON(buf, false))
+		assert.Equal(t, `{"author":"Philip K Dick","category":{"data":{"attributes":{"name":"novel"},"id":"1","type":"categories"}},"id":1,"title":"'Hogfather'","year":1998}`+"\n", buf.String())
+	})
+}
+
+func TestConcurrency(t *testing.T) {
+	cat := &Category{ID: 1, Name: "novel", Creator: &User{ID: 1, Name: "Tamas"}}
+	books := []*Book{}
+	for i := 1; i <= 50; i++ {
+		books = append(books, &Book{ID: i, Title: strconv.Itoa(i), Category: cat})
+	}
+
+	newResource := func(transformer fractal.Transformer) *fractal.Collection {
+		return fractal.NewCollection(
+			fractal.WithData(books),
+			fractal.WithResourceKey("books"),
+			fractal.WithTransformer(transformer),
+		)
+	}
+
+	manager := fractal.NewManager(nil)
+	manager.ParseIncludes([]string{"category.creator"})
+
+	expected, err := manager.CreateData(newResource(NewBookTransformer()), nil).ToMap()
+	assert.Nil(t, err)
+
+	manager.SetConcurrency(4)
+
+	t.Run("order preserved", func(t *testing.T) {
+		actual, err := manager.CreateData(newResource(&ContextBookTransformer{NewBookTransformer()}), nil).ToMap()
+
+		assert.Nil(t, err)
+		assert.Equal(t, expected, actual)
+	})
+
+	t.Run("first error", func(t *testing.T) {
+		transformed := 0
+		var mu sync.Mutex
+
+		transformer := &ContextUserTransformer{NewUserTransformer()}
+		users := []*User{}
+		for i := 1; i <= 50; i++ {
+			users = append(users, &User{ID: i})
+		}
+		users[10].ID = -1
+
+		items := fractal.FromRows(&bookRows{books: books}, func() (fractal.Any, error) {
+			mu.Lock()
+			defer mu.Unlock()
+			transformed++
+			return users[transformed-1], nil
+		})
+
+		_, err := manager.CreateData(fractal.NewCollection(
+			fractal.WithData(items),
+			fractal.WithTransformer(transformer),
+		), nil).ToMap()
+
+		assert.EqualError(t, err, "invalid user id -1")
+		assert.Less(t, transformed, len(users))
+	})
+
+	t.Run("own child scopes", func(t *testing.T) {
+		items := []*Book{}
+		for i := 1; i <= 50; i++ {
+			items = append(items, &Book{ID: i, Category: &Category{ID: i}})
+		}
+
+		// The transformers share their current scope between the items
+		transformer := NewCachedBookTransformer(&ScopedCategoryTransformer{})
+		resource := fractal.NewCollection(fractal.WithData(items), fractal.WithTransformer(transformer))
+
+		actual, err := manager.CreateData(resource, nil).ToMap()
+
+		assert.Nil(t, err)
+		for i, item := range actual["data"].([]fractal.Any) {
+			assert.Equal(t, fractal.M{"data": fractal.M{"id": i + 1}}, item.(fractal.M)["category"])
+		}
+	})
+
+	t.Run("panic", func(t *testing.T) {
+		items := []*Book{}
+		for i := 1; i <= 50; i++ {
+			items = append(items, &Book{ID: i})
+		}
+		items[10] = nil
+
+		transformer := fractal.T(func(_ *fractal.BaseTransformer, data fractal.Any) fractal.M {
+			return fractal.M{"id": data.(*Book).ID}
+		})
+
+		assert.Panics(t, func() {
+			manager.CreateData(fractal.NewCollection(
+				fractal.WithData(items),
+				fractal.WithTransformer(transformer),
+			), nil).ToMap()
+		})
+	})
+}
+
+type ContextBookTransformer struct {
+	*BookTransformer
+}
+
+func (t *ContextBookTransformer) TransformWithContext(ctx *fractal.TransformContext, data fractal.Any) (fractal.M, error) {
+	return t.Transform(data), nil
+}
+
+type ContextUserTransformer struct {
+	*UserTransformer
+}
+
+func (t *ContextUserTransformer) TransformWithContext(ctx *fractal.TransformContext, data fractal.Any) (fractal.M, error) {
+	return t.TransformWithError(data)
+}
+
+// ScopedCategoryTransformer transforms the category of its current scope instead of the data
+type ScopedCategoryTransformer struct {
+	fractal.BaseTransformer
+}
+
+func (t *ScopedCategoryTransformer) Transform(data fractal.Any) fractal.M {
+	// Give the other items the chance to set the current scope
+	runtime.Gosched()
+
+	return fractal.M{"id": t.GetCurrentScope().GetResource().GetData().(*Category).ID}
+}
+
+type BatchBookTransformer struct {
+	*BookTransformer
+	batches  int
+	includes int
+	result   func(items []fractal.Any) []fractal.Resource
+}
+
+func (t *BatchBookTransformer) Include(includeName string, data fractal.Any, params fractal.P) fractal.Resource {
+	t.includes++
+	return t.BookTransformer.Include(includeName, data, params)
+}
+
+func (t *BatchBookTransformer) IncludeBatch(ctx *fractal.TransformContext, includeName string, items []fractal.Any, params fractal.P) ([]fractal.Resource, error) {
+	t.batches++
+	return t.result(items), nil
+}
+
+func NewBatchBookTransformer(result func(items []fractal.Any) []fractal.Resource) *BatchBookTransformer {
+	t := &BatchBookTransformer{BookTransformer: NewBookTransformer(), result: result}
+	t.SetIncluder(t)
+	return t
+}
+
+func TestBatchIncluder(t *testing.T) {
+	books := []*Book{}
+	for i := 1; i <= 5; i++ {
+		books = append(books, &Book{ID: i, Category: &Category{ID: i}})
+	}
+
+	byBatch := func(items []fractal.Any) []fractal.Resource {
+		resources := make([]fractal.Resource, len(items))
+		for i, item := range items {
+			if id := item.(*Book).ID; id%2 == 1 {
+				resources[i] = fractal.NewPrimitive(
+					fractal.WithData(id*10),
+					fractal.WithTransformer(fractal.T(func(t *fractal.BaseTransformer, data fractal.Any) fractal.M {
+						return fractal.M{"id": data}
+					})),
+				)
+			}
+		}
+		return resources
+	}
+
+	for name, concurrency := range map[string]int{"sequential": 1, "concurrent": 3} {
+		t.Run(name, func(t *testing.T) {
+			manager := fractal.NewManager(nil).SetConcurrency(concurrency)
+			manager.ParseIncludes([]string{"category"})
+			manager.ParseFieldsets(map[string]string{"books": "id,category"})
+
+			transformer := NewBatchBookTransformer(byBatch)
+			resource := fractal.NewCollection(fractal.WithData(books), fractal.WithResourceKey("books"), fractal.WithTransformer(transformer))
+
+			actual, err := manager.CreateData(resource, nil).ToMap()
+
+			assert.Nil(t, err)
+			assert.Equal(t, 1, transformer.batches)
+			assert.Equal(t, 0, transformer.includes)
+			assert.Equal(t, []fractal.Any{
+				fractal.M{"id": 1, "category": fractal.M{"id": 10}},
+				fractal.M{"id": 2},
+				fractal.M{"id": 3, "category": fractal.M{"id": 30}},
+				fractal.M{"id": 4},
+				fractal.M{"id": 5, "category": fractal.M{"id": 50}},
+			}, actual["data"])
+		})
+	}
+
+	t.Run("streaming", func(t *testing.T) {
+		manager := fractal.NewManager(nil)
+		manager.ParseIncludes([]string{"category"})
+		manager.ParseFieldsets(map[string]string{"books": "id,category"})
+
+		newResource := func(transformer fractal.Transformer) fractal.Resource {
+			return fractal.NewCollection(fractal.WithData(books), fractal.WithResourceKey("books"), fractal.WithTransformer(transformer))
+		}
+
+		expected, err := manager.CreateData(newResource(NewBatchBookTransformer(byBatch)), nil).ToJSON()
+		assert.Nil(t, err)
+
+		transformer := NewBatchBookTransformer(byBatch)
+		buf := &bytes.Buffer{}
+
+		assert.Nil(t, manager.CreateData(newResource(transformer), nil).WriteJSON(buf))
+		assert.Equal(t, expected, buf.String())
+		assert.Equal(t, 1, transformer.batches)
+		assert.Equal(t, 0, transformer.includes)
+
+		transformer = NewBatchBookTransformer(byBatch)
+		buf.Reset()
+
+		assert.Nil(t, manager.CreateData(newResource(transformer), nil).WriteNDJSON(buf, false))
+		assert.Equal(t, `{"category":{"id":10},"id":1}
+{"id":2}
+{"category":{"id":30},"id":3}
+{"id":4}
+{"category":{"id":50},"id":5}
+`, buf.String())
+		assert.Equal(t, 1, transformer.batches)
+		assert.Equal(t, 0, transformer.includes)
+	})
+
+	t.Run("fallback", func(t *testing.T) {
+		manager := fractal.NewManager(nil)
+		manager.ParseIncludes([]string{"category"})
+
+		transformer := NewBatchBookTransformer(func(items []fractal.Any) []fractal.Resource {
+			return nil
+		})
+		resource := fractal.NewCollection(fractal.WithData(books), fractal.WithResourceKey("books"), fractal.WithTransformer(transformer))
+
+		_, err := manager.CreateData(resource, nil).ToMap()
+
+		assert.Nil(t, err)
+		assert.Equal(t, 1, transformer.batches)
+		assert.Equal(t, len(books), transformer.includes)
+	})
+
+	t.Run("mismatched resources", func(t *testing.T) {
+		manager := fractal.NewManager(nil)
+		manager.ParseIncludes([]string{"category"})
+
+		transformer := NewBatchBookTransformer(func(items []fractal.Any) []fractal.Resource {
+			return []fractal.Resource{nil}
+		})
+		resource := fractal.NewCollection(fractal.WithData(books), fractal.WithResourceKey("books"), fractal.WithTransformer(transformer))
+
+		_, err := manager.CreateData(resource, nil, fractal.WithIdentifier("books")).ToMap()
+
+		assert.EqualError(t, err, "books.category: the batch includer returned 1 resources for 5 items")
+	})
+}
+
+type CountingCategoryTransformer struct {
+	*CategoryTransformer
+	mu         sync.Mutex
+	transforms int
+	identify   bool
+}
+
+func (t *CountingCategoryTransformer) Transform(data fractal.Any) fractal.M {
+	t.mu.Lock()
+	t.transforms++
+	t.mu.Unlock()
+
+	return t.CategoryTransformer.Transform(data)
+}
+
+func (t *CountingCategoryTransformer) getTransforms() int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return t.transforms
+}
+
+type IdentifiedCategoryTransformer struct {
+	*CountingCategoryTransformer
+}
+
+func (t *IdentifiedCategoryTransformer) EntityID(data fractal.Any) fractal.Any {
+	if c := t.toCategory(data); c != nil {
+		return c.ID
+	}
+	return nil
+}
+
+type CachedBookTransformer struct {
+	*BookTransformer
+	categories fractal.Transformer
+}
+
+func (t *CachedBookTransformer) Include(includeName string, data fractal.Any, params fractal.P) fractal.Resource {
+	if b := t.toBook(data); b != nil && includeName == "category" {
+		return t.Item(
+			fractal.WithData(b.Category),
+			fractal.WithResourceKey("categories"),
+			fractal.WithTransformer(t.categories),
+		)
+	}
+	return nil
+}
+
+func NewCachedBookTransformer(categories fractal.Transformer) *CachedBookTransformer {
+	t := &CachedBookTransformer{BookTransformer: NewBookTransformer(), categories: categories}
+	t.SetIncluder(t)
+	return t
+}
+
+type ParentDependentBookTransformer struct {
+	*BookTransformer
+}
+
+func (t *ParentDependentBookTransformer) Include(includeName string, data fractal.Any, params fractal.P) fractal.Resource {
+	b := t.toBook(data)
+	return t.Item(
+		fractal.WithData(b.Category),
+		fractal.WithTransformer(fractal.T(func(_ *fractal.BaseTransformer, data fractal.Any) fractal.M {
+			return fractal.M{"id": data.(*Category).ID, "book_id": b.ID}
+		})),
+	)
+}
+
+func TestIncludeCache(t *testing.T) {
+	shared := &Category{ID: 1, Name: "Fantasy"}
+	sharedBooks := []*Book{{ID: 1, Category: shared}, {ID: 2, Category: shared}, {ID: 3, Category: shared}}
+	copiedBooks := []*Book{}
+	for i := 1; i <= 3; i++ {
+		copiedBooks = append(copiedBooks, &Book{ID: i, Category: &Category{ID: 1, Name: "Fantasy"}})
+	}
+
+	render := func(manager *fractal.Manager, books []*Book, categories fractal.Transformer) (fractal.M, error) {
+		manager.ParseIncludes([]string{"category"})
+		manager.ParseFieldsets(map[string]string{"books": "id,category"})
+
+		resource := fractal.NewCollection(
+			fractal.WithData(books),
+			fractal.WithResourceKey("books"),
+			fractal.WithTransformer(NewCachedBookTransformer(categories)),
+		)
+
+		return manager.CreateData(resource, nil).ToMap()
+	}
+
+	category := fractal.M{"data": fractal.M{"id": 1, "name": "Fantasy"}}
+	expected := []fractal.Any{
+		fractal.M{"id": 1, "category": category},
+		fractal.M{"id": 2, "category": category},
+		fractal.M{"id": 3, "category": category},
+	}
+
+	t.Run("same pointer", func(t *testing.T) {
+		categories := &CountingCategoryTransformer{CategoryTransformer: NewCategoryTransformer()}
+		manager := fractal.NewManager(nil).SetIncludeCache(true)
+
+		actual, err := render(manager, sharedBooks, categories)
+
+		assert.Nil(t, err)
+		assert.Equal(t, expected, actual["data"])
+		assert.Equal(t, 1, categories.getTransforms())
+
+		_, err = render(manager, sharedBooks, categories)
+
+		assert.Nil(t, err)
+		assert.Equal(t, 2, categories.getTransforms(), "renders should not share the cache")
+	})
+
+	t.Run("different pointers", func(t *testing.T) {
+		categories := &CountingCategoryTransformer{CategoryTransformer: NewCategoryTransformer()}
+
+		actual, err := render(fractal.NewManager(nil).SetIncludeCache(true), copiedBooks, categories)
+
+		assert.Nil(t, err)
+		assert.Equal(t, expected, actual["data"])
+		assert.Equal(t, 3, categories.getTransforms())
+	})
+
+	t.Run("entity identifier", func(t *testing.T) {
+		categories := &IdentifiedCategoryTransformer{
+			&CountingCategoryTransformer{CategoryTransformer: NewCategoryTransformer()},
+		}
+
+		actual, err := render(fractal.NewManager(nil).SetIncludeCache(true), copiedBooks, categories)
+
+		assert.Nil(t, err)
+		assert.Equal(t, expected, actual["data"])
+		assert.Equal(t, 1, categories.getTransforms())
+	})
+
+	t.Run("disabled by default", func(t *testing.T) {
+		categories := &CountingCategoryTransformer{CategoryTransformer: NewCategoryTransformer()}
+		manager := fractal.NewManager(nil)
+
+		actual, err := render(manager, sharedBooks, categories)
+
+		assert.Nil(t, err)
+		assert.Equal(t, expected, actual["data"])
+		assert.Equal(t, 3, categories.getTransforms())
+	})
+
+	t.Run("concurrent", func(t *testing.T) {
+		categories := &CountingCategoryTransformer{CategoryTransformer: NewCategoryTransformer()}
+		manager := fractal.NewManager(nil).SetIncludeCache(true).SetConcurrency(3)
+
+		actual, err := render(manager, sharedBooks, categories)
+
+		assert.Nil(t, err)
+		assert.Equal(t, expected, actual["data"])
+	})
+
+	t.Run("side-loaded", func(t *testing.T) {
+		categories := &CountingCategoryTransformer{CategoryTransformer: NewCategoryTransformer()}
+		manager := fractal.NewManager(nil).SetIncludeCache(true)
+		manager.SetSerializer(fractal.NewJsonApiSerializer(""))
+
+		actual, err := render(manager, sharedBooks, categories)
+
+		assert.Nil(t, err)
+		assert.Equal(t, 1, categories.getTransforms())
+		assert.Equal(t, []fractal.Any{
+			fractal.M{"type": "categories", "id": "1", "attributes": fractal.M{"name": "Fantasy"}},
+		}, actual["included"])
+	})
+
+	t.Run("parent dependent include", func(t *testing.T) {
+		transformer := &ParentDependentBookTransformer{NewBookTransformer()}
+		transformer.SetIncluder(transformer)
+
+		manager := fractal.NewManager(nil)
+		manager.ParseIncludes([]string{"category"})
+		manager.ParseFieldsets(map[string]string{"books": "id,category"})
+
+		resource := fractal.NewCollection(fractal.WithData(sharedBooks), fractal.WithResourceKey("books"), fractal.WithTransformer(transformer))
+		actual, err := manager.CreateData(resource, nil).ToMap()
+
+		assert.Nil(t, err)
+		assert.Equal(t, []fractal.Any{
+			fractal.M{"id": 1, "category": fractal.M{"data": fractal.M{"id": 1, "book_id": 1}}},
+			fractal.M{"id": 2, "category": fractal.M{"data": fractal.M{"id": 1, "book_id": 2}}},
+			fractal.M{"id": 3, "category": fractal.M{"data": fractal.M{"id": 1, "book_id": 3}}},
+		}, actual["data"])
+	})
+}
+
+func TestWildcardInclude(t *testing.T) {
+	cat := &Category{ID: 1, Name: "novel", Creator: &User{ID: 1, Name: "Tamas"}}
+	book := &Book{1, "Hogfather", 1998, "Philip K Dick", cat}
+
+	resource := fractal.NewItem(
+		fractal.WithData(book),
+		fractal.WithTransformer(NewBookTransformer()),
+	)
+
+	t.Run("current level", func(t *testing.T) {
+		manager := fractal.NewManager(nil)
+		manager.ParseIncludes([]string{"*"})
+
+		actual, err := manager.CreateData(resource, nil).ToMap()
+
+		assert.Nil(t, err)
+		assert.Equal(t, fractal.M{"data": fractal.M{"id": 1, "name": "novel"}}, actual["data"].(fractal.M)["category"])
+	})
+
+	t.Run("nested level", func(t *testing.T) {
+		manager := fractal.NewManager(nil)
+		manager.ParseIncludes([]string{"category.*"})
+		manager.ParseExcludes([]string{"category.books"})
+
+		actual, err := manager.CreateData(resource, nil).ToMap()
+
+		assert.Nil(t, err)
+		assert.Equal(t, fractal.M{"data": fractal.M{
+			"id":      1,
+			"name":    "novel",
+			"creator": fractal.M{"data": fractal.M{"id": 1, "name": "Tamas"}},
+		}}, actual["data"].(fractal.M)["category"])
+	})
+
+	t.Run("recursion limit", func(t *testing.T) {
+		manager := fractal.NewManager(nil).SetRecursionLimit(1)
+		manager.ParseIncludes([]string{"category.*"})
+
+		actual, err := manager.CreateData(resource, nil).ToMap()
+
+		assert.Nil(t, err)
+		assert.Equal(t, fractal.M{"data": fractal.M{"id": 1, "name": "novel"}}, actual["data"].(fractal.M)["category"])
+	})
+}
+
+func TestIncludeAlias(t *testing.T) {
+	categories := []*Category{
+		{ID: 1, Name: "novel", Creator: &User{ID: 1, Name: "Tamas"}},
+		{ID: 2, Name: "poetry", Creator: &User{ID: 2, Name: "Tamas"}},
+	}
+
+	newTransformer := func() *CategoryTransformer {
+		transformer := NewCategoryTransformer()
+		transformer.SetIncludeAlias("author", "creator").SetDeprecatedIncludeAlias("creator", "creator")
+		return transformer
+	}
+
+	render := func(manager *fractal.Manager) []fractal.Any {
+		resource := fractal.NewCollection(fractal.WithData(categories), fractal.WithTransformer(newTransformer()))
+
+		actual, err := manager.CreateData(resource, nil).ToMap()
+
+		assert.Nil(t, err)
+		return actual["data"].([]fractal.Any)
+	}
+
+	t.Run("public name", func(t *testing.T) {
+		manager := fractal.NewManager(nil)
+		manager.ParseIncludes([]string{"author"})
+
+		data := render(manager)
+
+		assert.Equal(t, fractal.M{"data": fractal.M{"id": 1, "name": "Tamas"}}, data[0].(fractal.M)["author"])
+		assert.NotContains(t, data[0], "creator")
+	})
+
+	t.Run("deprecated alias", func(t *testing.T) {
+		reported := [][]string{}
+		manager := fractal.NewManager(nil)
+		manager.SetDeprecatedIncludeHandler(func(scope *fractal.Scope, include, replacement string) {
+			reported = append(reported, []string{include, replacement})
+		})
+		manager.ParseIncludes([]string{"creator"})
+
+		data := render(manager)
+
+		assert.Equal(t, fractal.M{"data": fractal.M{"id": 2, "name": "Tamas"}}, data[1].(fractal.M)["creator"])
+		assert.NotContains(t, data[1], "author")
+		assert.Equal(t, [][]string{{"creator", "author"}}, reported)
+	})
+
+	t.Run("wildcard", func(t *testing.T) {
+		manager := fractal.NewManager(nil)
+		manager.ParseIncludes([]string{"*"})
+		manager.ParseExcludes([]string{"books"})
+
+		data := render(manager)
+
+		assert.Contains(t, data[0], "author")
+		assert.NotContains(t, data[0], "creator")
+	})
+
+	t.Run("params and exclude", func(t *testing.T) {
+		transformer := NewCategoryTransformer()
+		transformer.SetIncludeAlias("titles", "books")
+
+		category := &Category{ID: 1, Books: []*Book{{ID: 1}, {ID: 2}}}
+		manager := fractal.NewManager(nil)
+		manager.ParseIncludes([]string{"titles:limit(1)", "books"})
+		manager.ParseFieldsets(map[string]string{"categories": "titles"})
+
+		resource := fractal.NewItem(fractal.WithData(category), fractal.WithResourceKey("categories"), fractal.WithTransformer(transformer))
+		actual, err := manager.CreateData(resource, nil).ToMap()
+
+		assert.Nil(t, err)
+		assert.Len(t, actual["data"].(fractal.M)["titles"], 1)
+		assert.NotContains(t, actual["data"], "books")
+	})
+}
+
+type MethodCategoryTransformer struct {
+	*CategoryTransformer
+}
+
+func (t *MethodCategoryTransformer) IncludeCreator(data fractal.Any, params fractal.P) fractal.Resource {
+	return t.includeUser(data, params)
+}
+
+func (t *MethodCategoryTransformer) IncludeBooks(ctx *fractal.TransformContext, data fractal.Any, params fractal.P) (fractal.Resource, error) {
+	if ctx.GetScope() == nil {
+		return nil, errors.New("missing scope")
+	}
+	return t.includeBooks(data, params), nil
+}
+
+type InvalidMethodIncluder struct{}
+
+func (i *InvalidMethodIncluder) IncludeCreator(data fractal.Any) fractal.Resource {
+	return nil
+}
+
+func TestMethodIncluder(t *testing.T) {
+	cat := &Category{ID: 1, Name: "novel", Creator: &User{ID: 1, Name: "Tamas"}, Books: []*Book{{ID: 1}, {ID: 2}}}
+
+	t.Run("dispatch", func(t *testing.T) {
+		transformer := &MethodCategoryTransformer{NewCategoryTransformer()}
+		err := transformer.SetMethodIncluder(transformer)
+
+		assert.Nil(t, err)
+
+		manager := fractal.NewManager(nil)
+		manager.ParseIncludes([]string{"creator", "books:limit(1)"})
+
+		resource := fractal.NewItem(fractal.WithData(cat), fractal.WithTransformer(transformer))
+		actual, err := manager.CreateData(resource, nil).ToMap()
+
+		assert.Nil(t, err)
+		assert.Equal(t, fractal.M{"data": fractal.M{"id": 1, "name": "Tamas"}}, actual["data"].(fractal.M)["creator"])
+		assert.Len(t, actual["data"].(fractal.M)["books"], 1)
+	})
+
+	t.Run("missing methods", func(t *testing.T) {
+		transformer := &MethodCategoryTransformer{NewCategoryTransformer()}
+		transformer.SetAvailableIncludes([]string{"creator", "blog_posts"}).SetDefaultIncludes([]string{"top-rated"})
+
+		err := transformer.SetMethodIncluder(transformer)
+
+		assert.EqualError(t, err, "the includer *fractal_test.MethodCategoryTransformer has no include methods IncludeBlogPosts, IncludeTopRated")
+	})
+
+	t.Run("invalid signature", func(t *testing.T) {
+		transformer := NewCategoryTransformer()
+
+		err := transformer.SetMethodIncluder(&InvalidMethodIncluder{})
+
+		assert.EqualError(t, err, "the method IncludeCreator of *fractal_test.InvalidMethodIncluder for the include \"creator\" has an invalid signature func(*fractal_test.InvalidMethodIncluder, interface {}) fractal.Resource")
+	})
+}
+
+func TestStrictIncludes(t *testing.T) {
+	cat := &Category{ID: 1, Name: "novel", Creator: &User{ID: 1, Name: "Tamas"}}
+	books := []*Book{{ID: 1, Category: cat}, {ID: 2}}
+
+	render := func(strict bool, books []*Book, includes ...string) error {
+		manager := fractal.NewManager(nil).SetStrictIncludes(strict)
+		manager.ParseIncludes(includes)
+
+		resource := fractal.NewCollection(fractal.WithData(books), fractal.WithTransformer(NewBookTransformer()))
+		_, err := manager.CreateData(resource, nil).ToMap()
+		return err
+	}
+
+	t.Run("unknown includes", func(t *testing.T) {
+		err := render(true, books, "category.creatr", "titel")
+
+		var unknown *fractal.UnknownIncludesError
+		assert.True(t, errors.As(err, &unknown))
+		assert.Equal(t, []string{"category.creatr", "titel"}, unknown.Paths)
+		assert.Equal(t, map[string][]string{"category.creatr": {"category.creator"}}, unknown.Suggestions)
+		assert.EqualError(t, err, `unknown includes "category.creatr" (did you mean "category.creator"?), "titel"`)
+	})
+
+	t.Run("below unknown include", func(t *testing.T) {
+		err := render(true, books, "categories.creator")
+
+		assert.EqualError(t, err, `unknown includes "categories" (did you mean "category"?)`)
+	})
+
+	t.Run("known includes", func(t *testing.T) {
+		assert.Nil(t, render(true, books, "category.creator", "category.*"))
+	})
+
+	t.Run("never rendered", func(t *testing.T) {
+		assert.Nil(t, render(true, []*Book{{ID: 2}}, "category.creatr"))
+	})
+
+	t.Run("not strict", func(t *testing.T) {
+		assert.Nil(t, render(false, books, "category.creatr"))
+	})
+
+	t.Run("streamed", func(t *testing.T) {
+		manager := fractal.NewManager(nil).SetStrictIncludes(true)
+		manager.ParseIncludes([]string{"titel"})
+
+		resource := fractal.NewCollection(fractal.WithData(books), fractal.WithTransformer(NewBookTransformer()))
+		err := manager.CreateData(resource, nil).WriteJSON(&bytes.Buffer{})
+
+		assert.IsType(t, &fractal.UnknownIncludesError{}, err)
+	})
+}
+
+func TestAuthorization(t *testing.T) {
+	cat := &Category{ID: 1, Name: "novel", Creator: &User{ID: 1, Name: "Tamas"}}
+	book := &Book{1, "Hogfather", 1998, "Philip K Dick", cat}
+
+	render := func(manager *fractal.Manager, principal fractal.Any) (fractal.M, error) {
+		manager.ParseIncludes([]string{"category.creator"})
+
+		resource := fractal.NewItem(fractal.WithData(book), fractal.WithTransformer(NewBookTransformer()))
+		return manager.CreateData(resource, nil, fractal.WithPrincipal(principal)).ToMap()
+	}
+
+	t.Run("include", func(t *testing.T) {
+		paths := []string{}
+		manager := fractal.NewManager(nil).SetIncludeAuthorizer(func(scope *fractal.Scope, path string) fractal.Authorization {
+			paths = append(paths, path)
+			if path == "category.creator" && scope.GetPrincipal() != "admin" {
+				return fractal.AuthorizationDrop
+			}
+			return fractal.AuthorizationAllow
+		})
+
+		actual, err := render(manager, "guest")
+
+		assert.Nil(t, err)
+		assert.Equal(t, []string{"category", "category.creator"}, paths)
+		assert.NotContains(t, actual["data"].(fractal.M)["category"].(fractal.M)["data"], "creator")
+
+		actual, err = render(manager, "admin")
+
+		assert.Nil(t, err)
+		assert.Contains(t, actual["data"].(fractal.M)["category"].(fractal.M)["data"], "creator")
+	})
+
+	t.Run("rejected include", func(t *testing.T) {
+		manager := fractal.NewManager(nil).SetIncludeAuthorizer(func(scope *fractal.Scope, path string) fractal.Authorization {
+			if path == "category.creator" {
+				return fractal.AuthorizationReject
+			}
+			return fractal.AuthorizationAllow
+		})
+
+		_, err := render(manager, "guest")
+
+		var forbidden *fractal.ForbiddenError
+		assert.True(t, errors.As(err, &forbidden))
+		assert.Equal(t, "category.creator", forbidden.Path)
+		assert.EqualError(t, err, `category: access to "category.creator" is forbidden`)
+	})
+
+	t.Run("rejected include not requested by name", func(t *testing.T) {
+		manager := fractal.NewManager(nil).SetIncludeAuthorizer(func(scope *fractal.Scope, path string) fractal.Authorization {
+			if path == "category" {
+				return fractal.AuthorizationReject
+			}
+			return fractal.AuthorizationAllow
+		})
+
+		newResource := func() fractal.Resource {
+			transformer := NewBookTransformer()
+			transformer.SetDefaultIncludes([]string{"category"})
+			return fractal.NewItem(fractal.WithData(book), fractal.WithTransformer(transformer))
+		}
+
+		// Default include
+		manager.ParseIncludes([]string{})
+		actual, err := manager.CreateData(newResource(), nil).ToMap()
+
+		assert.Nil(t, err)
+		assert.NotContains(t, actual["data"], "category")
+
+		// Wildcard
+		manager.ParseIncludes([]string{"*"})
+		actual, err = manager.CreateData(fractal.NewItem(fractal.WithData(book), fractal.WithTransformer(NewBookTransformer())), nil).ToMap()
+
+		assert.Nil(t, err)
+		assert.NotContains(t, actual["data"], "category")
+
+		// Default include requested by name
+		manager.ParseIncludes([]string{"category"})
+		_, err = manager.CreateData(newResource(), nil).ToMap()
+
+		assert.EqualError(t, err, `access to "category" is forbidden`)
+	})
+
+	t.Run("field", func(t *testing.T) {
+		manager := fractal.NewManager(nil).SetFieldAuthorizer(func(scope *fractal.Scope, path string, data fractal.Any) fractal.Authorization {
+			switch path {
+			case "author":
+				return fractal.AuthorizationDrop
+			case "category.creator.name":
+				if scope.GetPrincipal() != "admin" {
+					return fractal.AuthorizationReject
+				}
+			}
+			return fractal.AuthorizationAllow
+		})
+
+		actual, err := render(manager, "admin")
+
+		assert.Nil(t, err)
+		assert.NotContains(t, actual["data"], "author")
+		assert.Contains(t, actual["data"], "title")
+
+		_, err = render(manager, "guest")
+
+		assert.IsType(t, &fractal.ScopeError{}, err)
+		assert.EqualError(t, err, `category.creator: access to "category.creator.name" is forbidden`)
+	})
+}
+
+func TestIncludeMeta(t *testing.T) {
+	cat := &Category{ID: 1, Name: "novel", Creator: &User{ID: 1, Name: "Tamas"}}
+
+	newManager := func() *fractal.Manager {
+		manager := fractal.NewManager(nil).SetIncludeMeta(true)
+		manager.ParseIncludes([]string{"category"})
+		return manager
+	}
+
+	t.Run("empty collection", func(t *testing.T) {
+		resource := fractal.NewCollection(fractal.WithData([]*Book{}), fractal.WithTransformer(NewBookTransformer()))
+
+		actual, err := newManager().CreateData(resource, nil).ToMap()
+
+		assert.Nil(t, err)
+		assert.Equal(t, fractal.M{
+			"data": []fractal.Any{},
+			"meta": fractal.M{"include": fractal.M{"available": []string{"category"}, "default": []string{}}},
+		}, actual)
+	})
+
+	t.Run("nested scope", func(t *testing.T) {
+		transformer := NewBookTransformer()
+		transformer.SetDefaultIncludes([]string{"category"})
+
+		manager := newManager().SetIncludeAuthorizer(func(scope *fractal.Scope, path string) fractal.Authorization {
+			if path == "category.books" {
+				return fractal.AuthorizationDrop
+			}
+			return fractal.AuthorizationAllow
+		})
+		resource := fractal.NewItem(fractal.WithData(&Book{ID: 1, Category: cat}), fractal.WithTransformer(transformer))
+
+		actual, err := manager.CreateData(resource, nil).ToMap()
+
+		assert.Nil(t, err)
+		assert.Equal(t, fractal.M{"available": []string{"category"}, "default": []string{"category"}}, actual["meta"].(fractal.M)["include"])
+		assert.Equal(t, fractal.M{"include": fractal.M{"available": []string{"creator"}, "default": []string{}}},
+			actual["data"].(fractal.M)["category"].(fractal.M)["meta"])
+	})
+
+	t.Run("user include meta", func(t *testing.T) {
+		resource := fractal.NewCollection(fractal.WithData([]*Book{}), fractal.WithTransformer(NewBookTransformer()))
+		resource.SetMeta(fractal.M{"include": fractal.M{"docs": "/docs/includes", "default": []string{"none"}}})
+
+		actual, err := newManager().CreateData(resource, nil).ToMap()
+
+		assert.Nil(t, err)
+		assert.Equal(t, fractal.M{
+			"include": fractal.M{"available": []string{"category"}, "default": []string{"none"}, "docs": "/docs/includes"},
+		}, actual["meta"])
+
+		resource = fractal.NewCollection(fractal.WithData([]*Book{}), fractal.WithTransformer(NewBookTransformer()))
+		resource.SetMeta(fractal.M{"include": "category"})
+
+		actual, err = newManager().CreateData(resource, nil).ToMap()
+
+		assert.Nil(t, err)
+		assert.Equal(t, fractal.M{"include": "category"}, actual["meta"])
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		resource := fractal.NewCollection(fractal.WithData([]*Book{}), fractal.WithTransformer(NewBookTransformer()))
+
+		actual, err := fractal.NewManager(nil).CreateData(resource, nil).ToMap()
+
+		assert.Nil(t, err)
+		assert.NotContains(t, actual, "meta")
+	})
+}
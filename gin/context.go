@@ -1,6 +1,7 @@
 package gin
 
 import (
+	"errors"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
@@ -10,7 +11,20 @@ import (
 // Context gin context with fractal extension
 type Context struct {
 	*gin.Context
-	manager *fractal.Manager
+	manager   *fractal.Manager
+	principal fractal.Any
+}
+
+// GetManager get the manager rendering the resources, e.g. to enable the strict includes
+func (c *Context) GetManager() *fractal.Manager {
+	return c.manager
+}
+
+// SetPrincipal set the principal the resources are rendered for, e.g. the current user,
+// which is passed to the include and field authorizers of the manager.
+func (c *Context) SetPrincipal(principal fractal.Any) *Context {
+	c.principal = principal
+	return c
 }
 
 func (c *Context) NoContent() {
@@ -34,16 +48,36 @@ func (c *Context) renderResource(resource fractal.Resource, callbacks ...Callbac
 	data, err := c.manager.CreateData(
 		resource, nil,
 		fractal.WithIdentifier(resource.GetResourceKey()),
+		fractal.WithContext(c.Request.Context()),
+		fractal.WithPrincipal(c.principal),
 	).ToMap()
 
 	if err != nil {
-		c.ErrorInternal(WithMessage(err.Error()))
+		c.renderError(err)
 	} else {
 		c.JSON(rsp.Status, data)
 	}
 }
 
-func (c *Context) Collection(items []fractal.Any, transformer fractal.Transformer, callbacks ...Callback) {
+// Respond the error of a render, the unknown includes are bad requests
+// and the rejected includes and fields are forbidden.
+func (c *Context) renderError(err error) {
+	var unknown *fractal.UnknownIncludesError
+	if errors.As(err, &unknown) {
+		c.ErrorBadRequest(WithUnknownIncludesError(unknown))
+		return
+	}
+
+	var forbidden *fractal.ForbiddenError
+	if errors.As(err, &forbidden) {
+		c.ErrorForbidden(WithError(forbidden))
+		return
+	}
+
+	c.ErrorInternal(WithMessage(err.Error()))
+}
+
+func (c *Context) Collection(items fractal.Any, transformer fractal.Transformer, callbacks ...Callback) {
 	resource := fractal.NewCollection(
 		fractal.WithData(items),
 		fractal.WithTransformer(transformer),
@@ -70,6 +104,50 @@ func (c *Context) Paginator(paginator Paginator, transformer fractal.Transformer
 	c.renderResource(resource)
 }
 
+func (c *Context) Cursor(cursor Cursor, transformer fractal.Transformer, callbacks ...Callback) {
+	resource := fractal.NewCollection(
+		fractal.WithData(cursor.GetItems()),
+		fractal.WithTransformer(transformer),
+	).SetCursor(cursor)
+
+	c.renderResource(resource, callbacks...)
+}
+
+// NDJSON render the items as newline delimited json, one transformed item per line,
+// the meta and the pagination of the collection are written as the trailing line.
+func (c *Context) NDJSON(items fractal.Any, transformer fractal.Transformer, callbacks ...Callback) {
+	resource := fractal.NewCollection(
+		fractal.WithData(items),
+		fractal.WithTransformer(transformer),
+	)
+
+	rsp := c.getResponse(resource, http.StatusOK, callbacks...)
+
+	c.Header("Content-Type", "application/x-ndjson")
+	c.Status(rsp.Status)
+
+	err := c.manager.CreateData(
+		resource, nil,
+		fractal.WithIdentifier(resource.GetResourceKey()),
+		fractal.WithContext(c.Request.Context()),
+		fractal.WithPrincipal(c.principal),
+	).WriteNDJSON(c.Writer, true)
+
+	if err == nil {
+		return
+	}
+
+	// The lines already written can not be taken back
+	if c.Writer.Written() {
+		c.Context.Error(err)
+		c.Abort()
+		return
+	}
+
+	c.Writer.Header().Del("Content-Type")
+	c.renderError(err)
+}
+
 func (c *Context) getErrorOption(opt *ErrorOption, mods ...ModErrorOption) *ErrorOption {
 	for _, mod := range mods {
 		mod(opt)
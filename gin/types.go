@@ -15,6 +15,12 @@ type Paginator interface {
 	SetItems(items []interface{})
 }
 
+// Cursor cursor with items
+type Cursor interface {
+	fractal.Cursor
+	GetItems() []interface{}
+}
+
 // Request request with binging
 type Request interface {
 	Messages() map[string]string
@@ -31,8 +37,23 @@ func H(h HandlerFunc) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		manager := fractal.NewManager(nil)
 		manager.SetSerializer(&fractal.ArraySerializer{})
-		manager.ParseIncludes(strings.Split(c.Query("include"), ","))
-		ctx := &Context{c, manager}
+		ctx := &Context{Context: c, manager: manager}
+
+		if err := manager.ParseIncludesE(strings.Split(c.Query("include"), ",")); err != nil {
+			ctx.AbortBadRequest(WithError(err))
+			return
+		}
+
+		if err := manager.ParseExcludes(strings.Split(c.Query("exclude"), ",")); err != nil {
+			ctx.AbortBadRequest(WithError(err))
+			return
+		}
+
+		if err := manager.ParseFieldsets(c.QueryMap("fields")); err != nil {
+			ctx.AbortBadRequest(WithError(err))
+			return
+		}
+
 		h(ctx)
 	}
 }
@@ -60,6 +81,24 @@ func WithError(err error) ModErrorOption {
 	}
 }
 
+// WithUnknownIncludesError set the unknown includes as the errors, along with the suggestions
+func WithUnknownIncludesError(err *fractal.UnknownIncludesError) ModErrorOption {
+	errors := map[string][]string{}
+
+	for _, path := range err.Paths {
+		messages := []string{"The include is unknown."}
+		for _, suggestion := range err.Suggestions[path] {
+			messages = append(messages, "Did you mean \""+suggestion+"\"?")
+		}
+		errors[path] = messages
+	}
+
+	return func(opt *ErrorOption) {
+		opt.Message = "The requested includes are unknown."
+		opt.Errors = errors
+	}
+}
+
 // WithError change error message by error
 func WithValidatorError(err error, req Request) ModErrorOption {
 	errors := map[string][]string{}
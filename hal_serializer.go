@@ -0,0 +1,222 @@
+package fractal
+
+// HalSerializer serializer for HAL documents, see https://stateless.group/hal_specification.html
+//
+// Includes are embedded into "_embedded" by their include name, the links
+// of the items and the pagination links are rendered into "_links".
+type HalSerializer struct {
+	//
+}
+
+// Collection serialize a collection
+func (s *HalSerializer) Collection(resourceKey string, data Any) M {
+	if resourceKey == "" {
+		resourceKey = DefaultResourceKey
+	}
+
+	return M{
+		"_embedded": M{resourceKey: data},
+	}
+}
+
+// Item serialize an item
+func (s *HalSerializer) Item(resourceKey string, data Any) M {
+	item := M{}
+
+	if m, ok := data.(M); ok {
+		for k, v := range m {
+			item[k] = v
+		}
+	}
+
+	return item
+}
+
+// Null serialize null resource
+func (s *HalSerializer) Null() M {
+	return nil
+}
+
+// IncludeData serialize include resource
+func (s *HalSerializer) IncludeData(resource Resource, data Any) Any {
+	return data
+}
+
+// Meta serialize the meta data, the "_links" are moved to the top level
+func (s *HalSerializer) Meta(meta M) M {
+	if len(meta) == 0 {
+		return nil
+	}
+
+	result := M{}
+	rest := M{}
+
+	for k, v := range meta {
+		if k == "_links" {
+			result[k] = v
+		} else {
+			rest[k] = v
+		}
+	}
+
+	if len(rest) > 0 {
+		result["meta"] = rest
+	}
+
+	return result
+}
+
+// Paginator serialize the paginator
+func (s *HalSerializer) Paginator(paginator Paginator) M {
+	currentPage := paginator.GetCurrentPage()
+	lastPage := paginator.GetLastPage()
+
+	links := M{
+		"self":  halLink(Link{Href: paginator.GetURL(currentPage)}),
+		"first": halLink(Link{Href: paginator.GetURL(1)}),
+		"last":  halLink(Link{Href: paginator.GetURL(lastPage)}),
+	}
+
+	if currentPage > 1 {
+		links["prev"] = halLink(Link{Href: paginator.GetURL(currentPage - 1)})
+	}
+
+	if currentPage < lastPage {
+		links["next"] = halLink(Link{Href: paginator.GetURL(currentPage + 1)})
+	}
+
+	return M{
+		"_links": links,
+		"pagination": M{
+			"total":        paginator.GetTotal(),
+			"count":        paginator.GetCount(),
+			"per_page":     paginator.GetPerPage(),
+			"current_page": currentPage,
+			"total_pages":  lastPage,
+		},
+	}
+}
+
+// Cursor serialize the cursor
+func (s *HalSerializer) Cursor(cursor Cursor) M {
+	result := M{
+		"cursor": M{
+			"current": cursor.GetCurrent(),
+			"prev":    cursor.GetPrev(),
+			"next":    cursor.GetNext(),
+			"count":   cursor.GetCount(),
+		},
+	}
+
+	if c, ok := cursor.(LinkedCursor); ok {
+		links := M{"self": halLink(Link{Href: c.GetURL(c.GetCurrent())})}
+		for rel, href := range cursorLinks(c) {
+			links[rel] = halLink(Link{Href: href})
+		}
+		result["_links"] = links
+	}
+
+	return result
+}
+
+// MergeIncludes embed the include data into the transformed data
+func (s *HalSerializer) MergeIncludes(transformed, included M) M {
+	if len(included) == 0 {
+		return transformed
+	}
+
+	embedded, ok := transformed["_embedded"].(M)
+	if !ok {
+		embedded = M{}
+	}
+
+	for k, v := range included {
+		embedded[k] = v
+	}
+
+	transformed["_embedded"] = embedded
+	return transformed
+}
+
+// SideloadIncludes indicates if includes should be side-loaded.
+func (s *HalSerializer) SideloadIncludes() bool {
+	return false
+}
+
+// InjectData is a hook for the serializer to inject custom data based on the relationships of the resource
+func (s *HalSerializer) InjectData(data, rawIncluded Any) Any {
+	return data
+}
+
+// InjectAvailableIncludeData is a hook for the serializer to inject custom data based on the available includes of the resource
+func (s *HalSerializer) InjectAvailableIncludeData(data M, availableIncludes []string) M {
+	return data
+}
+
+// FilterIncludes is hook for the serializer to modify the final list of includes.
+func (s *HalSerializer) FilterIncludes(included, data Any) Any {
+	return included
+}
+
+// MandatoryFields get the fields which are kept no matter which sparse fieldset is requested
+func (s *HalSerializer) MandatoryFields() []string {
+	return []string{"_links", "_embedded"}
+}
+
+// Links serialize the links of the resource into "_links"
+func (s *HalSerializer) Links(links Links) M {
+	return M{
+		"_links": halLinks(links),
+	}
+}
+
+// InjectLinks inject the links of the item into "_links"
+func (s *HalSerializer) InjectLinks(data M, links Links) M {
+	if len(links) == 0 {
+		return data
+	}
+
+	data["_links"] = mergeMaps(toM(data["_links"]), halLinks(links))
+	return data
+}
+
+// EmbedData embed the items of an included collection as a plain list
+func (s *HalSerializer) EmbedData(resource Resource, data M) Any {
+	if _, ok := resource.(*Collection); !ok {
+		return data
+	}
+
+	resourceKey := resource.GetResourceKey()
+	if resourceKey == "" {
+		resourceKey = DefaultResourceKey
+	}
+
+	if embedded, ok := data["_embedded"].(M); ok {
+		return embedded[resourceKey]
+	}
+
+	return data
+}
+
+// Render the link objects by their relations
+func halLinks(links Links) M {
+	rendered := M{}
+
+	for rel, link := range links {
+		rendered[rel] = halLink(link)
+	}
+
+	return rendered
+}
+
+// Render a link object with its href and attributes
+func halLink(link Link) M {
+	rendered := M{}
+
+	for k, v := range link.Attributes {
+		rendered[k] = v
+	}
+
+	rendered["href"] = link.Href
+	return rendered
+}
@@ -0,0 +1,115 @@
+package fractal_test
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/ibllex/go-fractal"
+	"github.com/ibllex/go-fractal/pagination"
+	"github.com/stretchr/testify/assert"
+)
+
+type LinkedArticleTransformer struct {
+	*fractal.StructTransformer
+}
+
+func (t *LinkedArticleTransformer) Links(data fractal.Any) fractal.Links {
+	return fractal.Links{
+		"self": {Href: fmt.Sprintf("/articles/%d", data.(*Article).ID)},
+		"find": {Href: "/articles{?q}", Attributes: fractal.M{"templated": true}},
+	}
+}
+
+func TestHalSerializer(t *testing.T) {
+	createdAt := time.Date(2021, 6, 1, 8, 0, 0, 0, time.UTC)
+	articles := []interface{}{
+		&Article{
+			Timestamps: Timestamps{CreatedAt: createdAt},
+			ID:         1,
+			Author:     &Author{ID: 1, Name: "Terry"},
+			Comments:   []*Author{{ID: 2, Name: "Sam"}},
+		},
+		&Article{Timestamps: Timestamps{CreatedAt: createdAt}, ID: 2},
+	}
+
+	manager := fractal.NewManager(nil)
+	manager.SetSerializer(&fractal.HalSerializer{})
+	manager.ParseIncludes([]string{"comments"})
+	manager.ParseFieldsets(map[string]string{"articles": "id,author,comments", "authors": "name"})
+
+	transformer := &LinkedArticleTransformer{fractal.NewStructTransformer(Article{})}
+	transformer.SetFormatter("upper", func(value fractal.Any) (fractal.Any, error) {
+		return value, nil
+	})
+	transformer.SetIncludeTransformer("comments", fractal.NewStructTransformer(Author{}))
+
+	t.Run("item", func(t *testing.T) {
+		resource := fractal.NewItem(
+			fractal.WithData(articles[0]),
+			fractal.WithResourceKey("articles"),
+			fractal.WithTransformer(transformer),
+		)
+
+		expected := fractal.M{
+			"id": 1,
+			"_links": fractal.M{
+				"self": fractal.M{"href": "/articles/1"},
+				"find": fractal.M{"href": "/articles{?q}", "templated": true},
+			},
+			"_embedded": fractal.M{
+				"author": fractal.M{"name": "Terry"},
+				"comments": []fractal.Any{
+					fractal.M{"id": 2, "name": "Sam"},
+				},
+			},
+		}
+
+		actual, err := manager.CreateData(resource, nil).ToMap()
+
+		assert.Nil(t, err)
+		assert.Equal(t, expected, actual)
+	})
+
+	t.Run("collection", func(t *testing.T) {
+		page := pagination.NewLengthAwarePaginator(
+			articles, 6, 2,
+			pagination.WithPath("/articles"),
+			pagination.WithCurrentPage(2),
+		)
+
+		resource := fractal.NewCollection(
+			fractal.WithData(articles),
+			fractal.WithResourceKey("articles"),
+			fractal.WithTransformer(transformer),
+		).SetPaginator(page)
+
+		actual, err := manager.CreateData(resource, nil).ToMap()
+
+		assert.Nil(t, err)
+		assert.Equal(t, fractal.M{
+			"self":  fractal.M{"href": "/articles?page=2"},
+			"first": fractal.M{"href": "/articles?page=1"},
+			"prev":  fractal.M{"href": "/articles?page=1"},
+			"next":  fractal.M{"href": "/articles?page=3"},
+			"last":  fractal.M{"href": "/articles?page=3"},
+		}, actual["_links"])
+		assert.Equal(t, fractal.M{
+			"total":        uint(6),
+			"count":        uint(2),
+			"per_page":     uint(2),
+			"current_page": uint(2),
+			"total_pages":  uint(3),
+		}, actual["meta"].(fractal.M)["pagination"])
+
+		items := actual["_embedded"].(fractal.M)["articles"].([]fractal.Any)
+		assert.Len(t, items, 2)
+		assert.Equal(t, fractal.M{
+			"id": 2,
+			"_links": fractal.M{
+				"self": fractal.M{"href": "/articles/2"},
+				"find": fractal.M{"href": "/articles{?q}", "templated": true},
+			},
+		}, items[1])
+	})
+}
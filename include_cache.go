@@ -0,0 +1,96 @@
+package fractal
+
+import (
+	"reflect"
+	"sync"
+)
+
+// EntityIdentifier is implemented by transformers which identify the entities they
+// transform, e.g. by their primary keys. Within a single render, an entity included
+// more than once at the same include path is transformed only once.
+type EntityIdentifier interface {
+	// EntityID get the identity of the entity, nil if it can not be identified
+	EntityID(data Any) Any
+}
+
+// renderState is shared by all the scopes of a single render
+type renderState struct {
+	mu       sync.Mutex
+	includes map[includeCacheKey]Any
+	// The deprecated include paths already reported
+	deprecated map[string]bool
+	// The includes offered by the transformers by the paths of the scopes, in strict mode
+	offered map[string][]string
+}
+
+// includeCacheKey identifies the included data of an entity at an include path
+type includeCacheKey struct {
+	identifier  string
+	resourceKey string
+	id          Any
+}
+
+func newRenderState() *renderState {
+	return &renderState{
+		includes:   map[includeCacheKey]Any{},
+		deprecated: map[string]bool{},
+		offered:    map[string][]string{},
+	}
+}
+
+// Mark the deprecated include path as reported, returns false if it is reported already
+func (r *renderState) markDeprecated(path string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.deprecated[path] {
+		return false
+	}
+
+	r.deprecated[path] = true
+	return true
+}
+
+func (r *renderState) load(key includeCacheKey) (Any, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	data, ok := r.includes[key]
+	return data, ok
+}
+
+func (r *renderState) store(key includeCacheKey, data Any) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.includes[key] = data
+}
+
+// Get the cache key of the included item resource of the scope, ok is false if
+// the resource can not be cached. Entities are identified by the EntityIdentifier
+// transformers, or by their pointers otherwise.
+func includeCacheKeyOf(scope *Scope) (key includeCacheKey, ok bool) {
+	resource, ok := scope.GetResource().(*Item)
+	if !ok {
+		return key, false
+	}
+
+	var id Any
+	data := resource.GetData()
+
+	if identifier, ok := resource.GetTransformer().(EntityIdentifier); ok {
+		id = identifier.EntityID(data)
+	} else if v := reflect.ValueOf(data); v.Kind() == reflect.Ptr && !v.IsNil() {
+		id = data
+	}
+
+	if id == nil || !reflect.TypeOf(id).Comparable() {
+		return key, false
+	}
+
+	return includeCacheKey{
+		identifier:  scope.GetIdentifier(""),
+		resourceKey: resource.GetResourceKey(),
+		id:          id,
+	}, true
+}
@@ -0,0 +1,122 @@
+package fractal
+
+import (
+	"sort"
+	"strings"
+)
+
+// Record the includes offered at the path of a scope, the path is recorded as
+// rendered even if no includes are offered.
+func (r *renderState) offerIncludes(path string, includes []string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	offered := r.offered[path]
+	for _, include := range includes {
+		if !containsString(offered, include) {
+			offered = append(offered, include)
+		}
+	}
+
+	r.offered[path] = offered
+}
+
+// Find the requested includes which are offered by no transformer at their levels, the
+// includes below the paths which are never rendered are skipped, which includes the
+// paths below the unknown ones as well.
+func (r *renderState) unknownIncludes(requested []string) *UnknownIncludesError {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var unknown *UnknownIncludesError
+
+	for _, include := range requested {
+		parent, segment := "", include
+		if i := strings.LastIndex(include, "."); i >= 0 {
+			parent, segment = include[:i], include[i+1:]
+		}
+
+		offered, rendered := r.offered[parent]
+		if !rendered || segment == WildcardInclude || containsString(offered, segment) {
+			continue
+		}
+
+		if unknown == nil {
+			unknown = &UnknownIncludesError{Suggestions: map[string][]string{}}
+		}
+
+		unknown.Paths = append(unknown.Paths, include)
+
+		for _, suggestion := range suggestIncludes(segment, offered) {
+			if parent != "" {
+				suggestion = parent + "." + suggestion
+			}
+			unknown.Suggestions[include] = append(unknown.Suggestions[include], suggestion)
+		}
+	}
+
+	return unknown
+}
+
+// Get the offered includes close to the unknown one, the closest first. Longer
+// includes are allowed to be more distant, by a third of their lengths.
+func suggestIncludes(include string, offered []string) []string {
+	distances := map[string]int{}
+	suggestions := []string{}
+
+	limit := 2
+	if l := len(include) / 3; l > limit {
+		limit = l
+	}
+
+	for _, o := range offered {
+		if d := levenshtein(include, o); d <= limit && d < len(include) {
+			distances[o] = d
+			suggestions = append(suggestions, o)
+		}
+	}
+
+	sort.Slice(suggestions, func(i, j int) bool {
+		if distances[suggestions[i]] != distances[suggestions[j]] {
+			return distances[suggestions[i]] < distances[suggestions[j]]
+		}
+		return suggestions[i] < suggestions[j]
+	})
+
+	return suggestions
+}
+
+// Get the edit distance between the strings
+func levenshtein(a, b string) int {
+	s, t := []rune(a), []rune(b)
+	prev := make([]int, len(t)+1)
+	curr := make([]int, len(t)+1)
+
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(s); i++ {
+		curr[0] = i
+		for j := 1; j <= len(t); j++ {
+			cost := 1
+			if s[i-1] == t[j-1] {
+				cost = 0
+			}
+			curr[j] = minInt(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+
+	return prev[len(t)]
+}
+
+func minInt(values ...int) int {
+	min := values[0]
+	for _, v := range values[1:] {
+		if v < min {
+			min = v
+		}
+	}
+	return min
+}
@@ -4,6 +4,7 @@ package fractal
 type Item struct {
 	data        Any
 	meta        M
+	links       Links
 	resourceKey string
 	transformer Transformer
 }
@@ -62,6 +63,27 @@ func (item *Item) SetMetaValue(key string, value Any) Resource {
 	return item
 }
 
+// GetLinks get the links
+func (item *Item) GetLinks() Links {
+	return item.links
+}
+
+// SetLinks set the links
+func (item *Item) SetLinks(links Links) Resource {
+	item.links = links
+	return item
+}
+
+// AddLink add a link by its relation, e.g. "self"
+func (item *Item) AddLink(rel, href string, attributes M) Resource {
+	if item.links == nil {
+		item.links = make(Links)
+	}
+
+	item.links[rel] = Link{Href: href, Attributes: attributes}
+	return item
+}
+
 // GetTransformer get the transformer
 func (item *Item) GetTransformer() Transformer {
 	return item.transformer
@@ -84,6 +106,7 @@ func NewItem(opts ...ModResourceOption) *Item {
 	return &Item{
 		data:        opt.data,
 		resourceKey: opt.resourceKey,
+		links:       opt.links,
 		transformer: opt.transformer,
 	}
 }
@@ -0,0 +1,230 @@
+package fractal
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+)
+
+// Iterator is a pull-based source of collection items, like the rows of a database
+// query, items are pulled one by one so the whole collection is never materialized.
+type Iterator interface {
+	// Next prepares the next item, returns false if there is no more item or an error happened
+	Next() bool
+	// Value get the current item
+	Value() Any
+	// Err get the error happened during the iteration, if any
+	Err() error
+}
+
+// Rows is a source of rows which are pulled lazily, like *sql.Rows
+type Rows interface {
+	Next() bool
+	Err() error
+	Close() error
+}
+
+// FromRows create an iterator over the rows, every row is scanned into an item by
+// the scan function, the rows are closed once the iteration is done or has failed.
+//
+//	rows, _ := db.Query("SELECT id, title FROM books")
+//	items := fractal.FromRows(rows, func() (fractal.Any, error) {
+//		b := &Book{}
+//		return b, rows.Scan(&b.ID, &b.Title)
+//	})
+func FromRows(rows Rows, scan func() (Any, error)) Iterator {
+	return &rowsIterator{rows: rows, scan: scan}
+}
+
+// MapOrder the order in which the values of a map are used as collection items
+type MapOrder int
+
+const (
+	// MapOrderAsc sort the values by their keys in ascending order
+	MapOrderAsc MapOrder = iota
+	// MapOrderDesc sort the values by their keys in descending order
+	MapOrderDesc
+	// MapOrderNone keep the random iteration order of the map
+	MapOrderNone
+)
+
+// Create an iterator over the data of a collection resource, which can be
+// a slice, an array, a map or an Iterator, nil is an empty collection.
+func newIterator(data Any, order MapOrder) (Iterator, error) {
+	switch d := data.(type) {
+	case nil:
+		return &sliceIterator{index: -1}, nil
+	case Iterator:
+		return d, nil
+	case []Any:
+		return &sliceIterator{items: d, index: -1}, nil
+	}
+
+	v := reflect.ValueOf(data)
+	if v.Kind() == reflect.Ptr && v.Elem().Kind() == reflect.Array {
+		v = v.Elem()
+	}
+
+	switch v.Kind() {
+	case reflect.Slice, reflect.Array:
+		return &reflectIterator{value: v, index: -1}, nil
+	case reflect.Map:
+		keys := v.MapKeys()
+		if order != MapOrderNone {
+			sortMapKeys(keys, order == MapOrderDesc)
+		}
+		return &mapIterator{value: v, keys: keys, index: -1}, nil
+	}
+
+	return nil, errors.New(
+		"the data of collection resource should be a slice, an array, a map or a fractal.Iterator",
+	)
+}
+
+// sliceIterator iterates over a []Any without reflection
+type sliceIterator struct {
+	items []Any
+	index int
+}
+
+func (it *sliceIterator) Next() bool {
+	it.index++
+	return it.index < len(it.items)
+}
+
+func (it *sliceIterator) Value() Any {
+	return it.items[it.index]
+}
+
+func (it *sliceIterator) Err() error {
+	return nil
+}
+
+// reflectIterator iterates over a slice or an array of any type
+type reflectIterator struct {
+	value reflect.Value
+	index int
+}
+
+func (it *reflectIterator) Next() bool {
+	it.index++
+	return it.index < it.value.Len()
+}
+
+func (it *reflectIterator) Value() Any {
+	return it.value.Index(it.index).Interface()
+}
+
+func (it *reflectIterator) Err() error {
+	return nil
+}
+
+// mapIterator iterates over the values of a map in the order of the keys
+type mapIterator struct {
+	value reflect.Value
+	keys  []reflect.Value
+	index int
+}
+
+func (it *mapIterator) Next() bool {
+	it.index++
+	return it.index < len(it.keys)
+}
+
+func (it *mapIterator) Value() Any {
+	return it.value.MapIndex(it.keys[it.index]).Interface()
+}
+
+func (it *mapIterator) Err() error {
+	return nil
+}
+
+// rowsIterator iterates over rows by scanning every row into an item
+type rowsIterator struct {
+	rows   Rows
+	scan   func() (Any, error)
+	value  Any
+	err    error
+	closed bool
+}
+
+func (it *rowsIterator) Next() bool {
+	if it.closed {
+		return false
+	}
+
+	if !it.rows.Next() {
+		it.Close()
+		return false
+	}
+
+	if it.value, it.err = it.scan(); it.err != nil {
+		it.Close()
+		return false
+	}
+
+	return true
+}
+
+func (it *rowsIterator) Value() Any {
+	return it.value
+}
+
+func (it *rowsIterator) Err() error {
+	if it.err != nil {
+		return it.err
+	}
+	return it.rows.Err()
+}
+
+// Close close the rows, it is safe to be called more than once
+func (it *rowsIterator) Close() error {
+	if it.closed {
+		return nil
+	}
+
+	it.closed = true
+	if err := it.rows.Close(); err != nil && it.err == nil {
+		it.err = err
+	}
+
+	return it.err
+}
+
+// Close the iterator if it holds resources, like the rows of a database
+// query, in case the iteration is stopped before it is done.
+func closeIterator(it Iterator) {
+	if c, ok := it.(io.Closer); ok {
+		c.Close()
+	}
+}
+
+// Sort the keys of a map, keys of numeric, string and bool kinds are compared
+// by their values, any other keys are compared by their formatted values.
+func sortMapKeys(keys []reflect.Value, desc bool) {
+	sort.SliceStable(keys, func(i, j int) bool {
+		if desc {
+			return lessValue(keys[j], keys[i])
+		}
+		return lessValue(keys[i], keys[j])
+	})
+}
+
+func lessValue(a, b reflect.Value) bool {
+	switch a.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return a.Int() < b.Int()
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return a.Uint() < b.Uint()
+	case reflect.Float32, reflect.Float64:
+		return a.Float() < b.Float()
+	case reflect.String:
+		return a.String() < b.String()
+	case reflect.Bool:
+		return !a.Bool() && b.Bool()
+	}
+
+	return fmt.Sprint(a.Interface()) < fmt.Sprint(b.Interface())
+}
@@ -0,0 +1,440 @@
+package fractal
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// JsonApiSerializer serializer for JSON:API documents, see https://jsonapi.org
+type JsonApiSerializer struct {
+	baseURL string
+}
+
+// Collection serialize a collection
+func (s *JsonApiSerializer) Collection(resourceKey string, data Any) M {
+	resources := []Any{}
+
+	if items, ok := data.([]Any); ok {
+		for _, item := range items {
+			resources = append(resources, s.resourceObject(resourceKey, item))
+		}
+	}
+
+	return M{
+		"data": resources,
+	}
+}
+
+// Item serialize an item
+func (s *JsonApiSerializer) Item(resourceKey string, data Any) M {
+	return M{
+		"data": s.resourceObject(resourceKey, data),
+	}
+}
+
+// Build the resource object of a single transformed item, the "id" of the item is
+// pulled out of the attributes, so do the "links" and "meta" if any.
+func (s *JsonApiSerializer) resourceObject(resourceKey string, data Any) M {
+	resource := M{"type": resourceKey}
+	attributes := M{}
+
+	if m, ok := data.(M); ok {
+		for k, v := range m {
+			attributes[k] = v
+		}
+	}
+
+	if id, ok := attributes["id"]; ok {
+		if id != nil {
+			resource["id"] = fmt.Sprint(id)
+		}
+		delete(attributes, "id")
+	}
+
+	links := M{}
+	if custom, ok := attributes["links"].(M); ok {
+		links = custom
+		delete(attributes, "links")
+	}
+
+	if meta, ok := attributes["meta"]; ok {
+		resource["meta"] = meta
+		delete(attributes, "meta")
+	}
+
+	resource["attributes"] = attributes
+
+	if s.shouldIncludeLinks() {
+		if _, ok := links["self"]; !ok {
+			links["self"] = s.baseURL + "/" + resourceKey + "/" + s.getID(resource)
+		}
+	}
+
+	if len(links) > 0 {
+		resource["links"] = links
+	}
+
+	return resource
+}
+
+// Null serialize null resource
+func (s *JsonApiSerializer) Null() M {
+	return M{
+		"data": nil,
+	}
+}
+
+// IncludeData serialize include resource, all included resource objects are
+// collected into the top-level "included" array without duplicates.
+func (s *JsonApiSerializer) IncludeData(resource Resource, data Any) Any {
+	rawIncluded, _ := data.([]M)
+	included := []Any{}
+	linked := map[string]bool{}
+
+	add := func(object Any) {
+		if o, ok := object.(M); ok {
+			if key := s.getKey(o); key == "" || !linked[key] {
+				linked[key] = true
+				included = append(included, o)
+			}
+		}
+	}
+
+	// Pull out the data included by nested scopes first
+	for _, includes := range rawIncluded {
+		for _, includeName := range sortedKeys(includes) {
+			if child, ok := includes[includeName].(M); ok {
+				if nested, ok := child["included"].([]Any); ok {
+					for _, object := range nested {
+						add(object)
+					}
+				}
+			}
+		}
+	}
+
+	for _, includes := range rawIncluded {
+		for _, includeName := range sortedKeys(includes) {
+			if child, ok := includes[includeName].(M); ok {
+				switch d := child["data"].(type) {
+				case M:
+					add(d)
+				case []Any:
+					for _, object := range d {
+						add(object)
+					}
+				}
+			}
+		}
+	}
+
+	if len(included) == 0 {
+		return M{}
+	}
+
+	return M{
+		"included": included,
+	}
+}
+
+// Meta serialize the meta data, pagination and cursor links are moved to the top-level links
+func (s *JsonApiSerializer) Meta(meta M) M {
+	if len(meta) == 0 {
+		return nil
+	}
+
+	result := M{}
+	copied := M{}
+
+	for k, v := range meta {
+		copied[k] = v
+	}
+
+	for _, key := range []string{"pagination", "cursor"} {
+		if pagination, ok := copied[key].(M); ok {
+			if links, ok := pagination["links"]; ok {
+				result["links"] = toM(links)
+
+				p := M{}
+				for k, v := range pagination {
+					if k != "links" {
+						p[k] = v
+					}
+				}
+				copied[key] = p
+			}
+		}
+	}
+
+	result["meta"] = copied
+	return result
+}
+
+// Paginator serialize the paginator
+func (s *JsonApiSerializer) Paginator(paginator Paginator) M {
+	currentPage := paginator.GetCurrentPage()
+	lastPage := paginator.GetLastPage()
+
+	pagination := M{
+		"total":        paginator.GetTotal(),
+		"count":        paginator.GetCount(),
+		"per_page":     paginator.GetPerPage(),
+		"current_page": currentPage,
+		"total_pages":  lastPage,
+	}
+
+	links := M{
+		"self":  paginator.GetURL(currentPage),
+		"first": paginator.GetURL(1),
+		"last":  paginator.GetURL(lastPage),
+	}
+
+	if currentPage > 1 {
+		links["prev"] = paginator.GetURL(currentPage - 1)
+	}
+
+	if currentPage < lastPage {
+		links["next"] = paginator.GetURL(currentPage + 1)
+	}
+
+	pagination["links"] = links
+
+	return M{
+		"pagination": pagination,
+	}
+}
+
+// Cursor serialize the cursor
+func (s *JsonApiSerializer) Cursor(cursor Cursor) M {
+	data := M{
+		"current": cursor.GetCurrent(),
+		"prev":    cursor.GetPrev(),
+		"next":    cursor.GetNext(),
+		"count":   cursor.GetCount(),
+	}
+
+	if c, ok := cursor.(LinkedCursor); ok {
+		data["links"] = cursorLinks(c)
+	}
+
+	return M{
+		"cursor": data,
+	}
+}
+
+// MergeIncludes merge include data with transformed data,
+// includes are always side-loaded so they are never merged.
+func (s *JsonApiSerializer) MergeIncludes(transformed, included M) M {
+	return transformed
+}
+
+// SideloadIncludes indicates if includes should be side-loaded.
+func (s *JsonApiSerializer) SideloadIncludes() bool {
+	return true
+}
+
+// InjectData inject the relationships of the resource objects
+func (s *JsonApiSerializer) InjectData(data, rawIncluded Any) Any {
+	document, ok := data.(M)
+	if !ok {
+		return data
+	}
+
+	rawIncludedData, _ := rawIncluded.([]M)
+
+	switch d := document["data"].(type) {
+	case M:
+		if len(rawIncludedData) > 0 {
+			s.fillRelationships(d, rawIncludedData[0])
+		}
+	case []Any:
+		for i, resource := range d {
+			if r, ok := resource.(M); ok && i < len(rawIncludedData) {
+				s.fillRelationships(r, rawIncludedData[i])
+			}
+		}
+	}
+
+	return document
+}
+
+// Fill the relationships of a single resource object with the linkage of its includes
+func (s *JsonApiSerializer) fillRelationships(resource M, includes M) {
+	relationships := M{}
+
+	for includeName, include := range includes {
+		relationship := M{"data": nil}
+
+		switch child := include.(type) {
+		case nil:
+		case M:
+			switch d := child["data"].(type) {
+			case M:
+				relationship["data"] = s.linkage(d)
+			case []Any:
+				linkage := []Any{}
+				for _, object := range d {
+					if o, ok := object.(M); ok {
+						linkage = append(linkage, s.linkage(o))
+					}
+				}
+				relationship["data"] = linkage
+			}
+
+			if meta, ok := child["meta"]; ok {
+				relationship["meta"] = meta
+			}
+		default:
+			// Primitive includes can not be linked
+			continue
+		}
+
+		if s.shouldIncludeLinks() {
+			self := s.baseURL + "/" + s.getType(resource) + "/" + s.getID(resource)
+			relationship["links"] = M{
+				"self":    self + "/relationships/" + includeName,
+				"related": self + "/" + includeName,
+			}
+		}
+
+		relationships[includeName] = relationship
+	}
+
+	if len(relationships) > 0 {
+		resource["relationships"] = relationships
+	}
+}
+
+// Build the resource identifier object of the resource object
+func (s *JsonApiSerializer) linkage(resource M) M {
+	return M{
+		"type": resource["type"],
+		"id":   resource["id"],
+	}
+}
+
+// InjectAvailableIncludeData is a hook for the serializer to inject custom data based on the available includes of the resource
+func (s *JsonApiSerializer) InjectAvailableIncludeData(data M, availableIncludes []string) M {
+	return data
+}
+
+// FilterIncludes remove the root resource objects from the included data
+func (s *JsonApiSerializer) FilterIncludes(included, data Any) Any {
+	includedData, ok := included.(M)
+	if !ok {
+		return included
+	}
+
+	objects, ok := includedData["included"].([]Any)
+	if !ok {
+		return included
+	}
+
+	roots := map[string]bool{}
+	if document, ok := data.(M); ok {
+		switch d := document["data"].(type) {
+		case M:
+			roots[s.getKey(d)] = true
+		case []Any:
+			for _, resource := range d {
+				if r, ok := resource.(M); ok {
+					roots[s.getKey(r)] = true
+				}
+			}
+		}
+	}
+
+	filtered := []Any{}
+	for _, object := range objects {
+		if o, ok := object.(M); !ok || s.getKey(o) == "" || !roots[s.getKey(o)] {
+			filtered = append(filtered, object)
+		}
+	}
+
+	if len(filtered) == 0 {
+		return M{}
+	}
+
+	return M{
+		"included": filtered,
+	}
+}
+
+// MandatoryFields get the fields which are kept no matter which sparse fieldset is requested
+func (s *JsonApiSerializer) MandatoryFields() []string {
+	return []string{"id"}
+}
+
+// Links serialize the links of the resource into the top-level links
+func (s *JsonApiSerializer) Links(links Links) M {
+	return M{
+		"links": jsonApiLinks(links),
+	}
+}
+
+// InjectLinks inject the links of the item into the links of its resource object
+func (s *JsonApiSerializer) InjectLinks(data M, links Links) M {
+	if len(links) == 0 {
+		return data
+	}
+
+	data["links"] = mergeMaps(toM(data["links"]), jsonApiLinks(links))
+	return data
+}
+
+// Render the links as plain hrefs, or as link objects
+// holding their attributes as meta if they have any.
+func jsonApiLinks(links Links) M {
+	rendered := M{}
+
+	for rel, link := range links {
+		if len(link.Attributes) == 0 {
+			rendered[rel] = link.Href
+		} else {
+			rendered[rel] = M{"href": link.Href, "meta": link.Attributes}
+		}
+	}
+
+	return rendered
+}
+
+func (s *JsonApiSerializer) shouldIncludeLinks() bool {
+	return s.baseURL != ""
+}
+
+func (s *JsonApiSerializer) getType(resource M) string {
+	t, _ := resource["type"].(string)
+	return t
+}
+
+func (s *JsonApiSerializer) getID(resource M) string {
+	id, _ := resource["id"].(string)
+	return id
+}
+
+// Get the key identifying the resource object, empty if the object has no id
+func (s *JsonApiSerializer) getKey(resource M) string {
+	id := s.getID(resource)
+	if id == "" {
+		return ""
+	}
+	return s.getType(resource) + ":" + id
+}
+
+func sortedKeys(m M) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// NewJsonApiSerializer create new JSON:API serializer, the base url is used
+// to build the links of resource objects, no links are built if it is empty.
+func NewJsonApiSerializer(baseURL string) *JsonApiSerializer {
+	return &JsonApiSerializer{
+		baseURL: strings.TrimRight(baseURL, "/"),
+	}
+}
@@ -0,0 +1,156 @@
+package fractal_test
+
+import (
+	"testing"
+
+	"github.com/ibllex/go-fractal"
+	"github.com/ibllex/go-fractal/pagination"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestJsonApiItem(t *testing.T) {
+	cat := &Category{ID: 1, Name: "novel", Creator: &User{ID: 2, Name: "Tamas"}}
+	book := Book{1, "Hogfather", 1998, "Philip K Dick", cat}
+
+	manager := fractal.NewManager(nil)
+	manager.SetSerializer(fractal.NewJsonApiSerializer(""))
+	manager.ParseIncludes([]string{"category.creator"})
+
+	resource := fractal.NewItem(
+		fractal.WithData(book),
+		fractal.WithResourceKey("books"),
+		fractal.WithTransformer(NewBookTransformer()),
+	)
+
+	expected := fractal.M{
+		"data": fractal.M{
+			"type": "books",
+			"id":   "1",
+			"attributes": fractal.M{
+				"title":  "'Hogfather'",
+				"year":   1998,
+				"author": "Philip K Dick",
+			},
+			"relationships": fractal.M{
+				"category": fractal.M{
+					"data": fractal.M{"type": "categories", "id": "1"},
+				},
+			},
+		},
+		"included": []fractal.Any{
+			fractal.M{
+				"type":       "users",
+				"id":         "2",
+				"attributes": fractal.M{"name": "Tamas"},
+			},
+			fractal.M{
+				"type":       "categories",
+				"id":         "1",
+				"attributes": fractal.M{"name": "novel"},
+				"relationships": fractal.M{
+					"creator": fractal.M{
+						"data": fractal.M{"type": "users", "id": "2"},
+					},
+				},
+			},
+		},
+	}
+
+	actual, err := manager.CreateData(resource, nil).ToMap()
+
+	assert.Nil(t, err)
+	assert.Equal(t, expected, actual)
+}
+
+func TestJsonApiCollection(t *testing.T) {
+	cat := &Category{ID: 1, Name: "novel"}
+	books := []fractal.Any{
+		Book{1, "Hogfather", 1998, "Philip K Dick", cat},
+		Book{2, "Game Of Kill Everyone", 2014, "George R. R. Satan", cat},
+	}
+
+	page := pagination.NewLengthAwarePaginator(
+		books, 6, 2,
+		pagination.WithPath("https://www.example.com/books"),
+		pagination.WithCurrentPage(2),
+	)
+
+	manager := fractal.NewManager(nil)
+	manager.SetSerializer(fractal.NewJsonApiSerializer("https://www.example.com/"))
+	manager.ParseIncludes([]string{"category"})
+	manager.ParseFieldsets(map[string]string{"books": "title,category"})
+
+	resource := fractal.NewCollection(
+		fractal.WithData(books),
+		fractal.WithResourceKey("books"),
+		fractal.WithTransformer(NewBookTransformer()),
+	).SetPaginator(page)
+
+	book := func(id, title string) fractal.M {
+		return fractal.M{
+			"type":       "books",
+			"id":         id,
+			"attributes": fractal.M{"title": title},
+			"links":      fractal.M{"self": "https://www.example.com/books/" + id},
+			"relationships": fractal.M{
+				"category": fractal.M{
+					"data": fractal.M{"type": "categories", "id": "1"},
+					"links": fractal.M{
+						"self":    "https://www.example.com/books/" + id + "/relationships/category",
+						"related": "https://www.example.com/books/" + id + "/category",
+					},
+				},
+			},
+		}
+	}
+
+	expected := fractal.M{
+		"data": []fractal.Any{
+			book("1", "'Hogfather'"),
+			book("2", "'Game Of Kill Everyone'"),
+		},
+		"included": []fractal.Any{
+			fractal.M{
+				"type":       "categories",
+				"id":         "1",
+				"attributes": fractal.M{"name": "novel"},
+				"links":      fractal.M{"self": "https://www.example.com/categories/1"},
+			},
+		},
+		"meta": fractal.M{
+			"pagination": fractal.M{
+				"total":        uint(6),
+				"count":        uint(2),
+				"per_page":     uint(2),
+				"current_page": uint(2),
+				"total_pages":  uint(3),
+			},
+		},
+		"links": fractal.M{
+			"self":  "https://www.example.com/books?page=2",
+			"first": "https://www.example.com/books?page=1",
+			"prev":  "https://www.example.com/books?page=1",
+			"next":  "https://www.example.com/books?page=3",
+			"last":  "https://www.example.com/books?page=3",
+		},
+	}
+
+	actual, err := manager.CreateData(resource, nil).ToMap()
+
+	assert.Nil(t, err)
+	assert.Equal(t, expected, actual)
+}
+
+func TestJsonApiFilterIncludes(t *testing.T) {
+	included := fractal.M{"included": []fractal.Any{
+		fractal.M{"type": "books", "id": "1"},
+		fractal.M{"type": "users", "id": "1"},
+	}}
+	data := fractal.M{"data": fractal.M{"type": "books", "id": "1"}}
+
+	actual := fractal.NewJsonApiSerializer("").FilterIncludes(included, data)
+
+	assert.Equal(t, fractal.M{"included": []fractal.Any{
+		fractal.M{"type": "users", "id": "1"},
+	}}, actual)
+}
@@ -0,0 +1,11 @@
+package fractal
+
+// Link is a hypermedia link, the attributes are rendered along with
+// the href by the serializers supporting them, e.g. "title" or "templated"
+type Link struct {
+	Href       string
+	Attributes M
+}
+
+// Links the hypermedia links keyed by their relation, e.g. "self"
+type Links map[string]Link
@@ -1,6 +1,10 @@
 package fractal
 
-import "strings"
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
 
 // Manager allows users to create the "root scope" easily
 type Manager struct {
@@ -13,8 +17,25 @@ type Manager struct {
 	includeParams      map[string]P
 	// Upper limit to how many levels of included data are allowed.
 	recursionLimit int
+	// Number of workers transforming the items of a collection.
+	concurrency int
+	// Reuse the included data of the same entity within a render.
+	includeCacheEnabled bool
+	// Called when an include is requested by a deprecated alias.
+	deprecatedIncludeHandler DeprecatedIncludeHandler
+	// Fail the render if an include is requested but offered by no transformer.
+	strictIncludes bool
+	// Decide if the principal of the scope is allowed to see the includes and the fields.
+	includeAuthorizer IncludeAuthorizer
+	fieldAuthorizer   FieldAuthorizer
+	// Publish the available and default includes of every scope into the meta.
+	includeMeta bool
 }
 
+// DeprecatedIncludeHandler handles an include requested by a deprecated alias, include is
+// the full include path of the alias and replacement is the include name to use instead.
+type DeprecatedIncludeHandler func(scope *Scope, include, replacement string)
+
 // CreateData is main method to kick this all off.
 // Make a resource then pass it over, and use toJson()
 func (m *Manager) CreateData(resource Resource, parentScope *Scope, opts ...ModScopeOption) *Scope {
@@ -24,36 +45,272 @@ func (m *Manager) CreateData(resource Resource, parentScope *Scope, opts ...ModS
 	return m.scopeFactory.CreateScopeFor(m, resource, opts...)
 }
 
-// ParseIncludes parse include params
+// ParseIncludes parse include params, an include can carry modifiers separated by ":"
+// and the arguments of a modifier are separated by "|",
+// e.g. "comments:limit(5|1):order(created_at|desc)".
+// A wildcard like "*" or "author.*" requests all the available includes at its level,
+// it should be the last segment of the include and can not carry modifiers.
+// The modifiers of an include requested more than once are merged.
+// Malformed includes are skipped, see ParseIncludesE for the syntax errors.
 func (m *Manager) ParseIncludes(includes []string) *Manager {
+	m.ParseIncludesE(includes)
+	return m
+}
+
+// ParseIncludesE parse include params like ParseIncludes, the first syntax error is returned.
+func (m *Manager) ParseIncludesE(includes []string) error {
 	// Wipe these before we go again
 	m.requestedIncludes = []string{}
-	// m.includeParams = map[string][]string{}
+	m.includeParams = map[string]P{}
 
-	var includeName, allModifiersStr string
+	var err error
 
 	for _, include := range includes {
-		includeName, allModifiersStr = m.explodeInclude(include, ":")
-		allModifiersStr, _ = m.explodeInclude(allModifiersStr, ".")
+		if e := m.parseInclude(strings.TrimSpace(include)); e != nil && err == nil {
+			err = e
+		}
+	}
+
+	m.autoIncludeParents()
+	return err
+}
+
+func (m *Manager) parseInclude(include string) error {
+	if include == "" {
+		return nil
+	}
+
+	var params P
+	var subRelations string
+
+	includeName, allModifiersStr := m.explodeInclude(include, ":")
+	if !isValidIncludeName(includeName) {
+		return &IncludeSyntaxError{Include: include, Reason: "empty include segment"}
+	}
+
+	if reason := checkWildcard(includeName); reason != "" {
+		return &IncludeSyntaxError{Include: include, Reason: reason}
+	}
+
+	if strings.Contains(include, ":") {
+		if isWildcardInclude(includeName) {
+			return &IncludeSyntaxError{Include: include, Reason: "a wildcard include can not carry modifiers"}
+		}
+
+		var err error
+		if params, subRelations, err = parseModifiers(allModifiersStr); err != nil {
+			return &IncludeSyntaxError{Include: include, Reason: err.Error()}
+		}
+	}
+
+	// Trim it down to a cool level of recursion
+	trimmedName := m.trimToAcceptableRecursionLevel(includeName)
+
+	// Modifiers of an include beyond the recursion limit are trimmed along with it
+	if params != nil && trimmedName == includeName {
+		if err := m.mergeIncludeParams(include, includeName, params); err != nil {
+			return err
+		}
+	}
+
+	if !m.hasRequestInclude(trimmedName) {
+		m.requestedIncludes = append(m.requestedIncludes, trimmedName)
+	}
+
+	// e.g. "comments:limit(5).author" requests "comments.author" as well
+	if subRelations != "" {
+		return m.parseInclude(includeName + "." + subRelations)
+	}
 
-		// Trim it down to a cool level of recursion
-		includeName = m.trimToAcceptableRecursionLevel(includeName)
+	return nil
+}
 
-		if m.hasRequestInclude(includeName) {
+// Merge the modifiers into the ones of the include requested before,
+// a modifier can not be given more than once for the same include.
+func (m *Manager) mergeIncludeParams(include, includeName string, params P) error {
+	merged, ok := m.includeParams[includeName]
+	if !ok {
+		m.includeParams[includeName] = params
+		return nil
+	}
+
+	for name := range params {
+		if merged.Has(name) {
+			return &IncludeSyntaxError{Include: include, Reason: fmt.Sprintf("duplicate modifier \"%s\"", name)}
+		}
+	}
+
+	for name, args := range params {
+		merged[name] = args
+	}
+
+	return nil
+}
+
+// Parse the modifiers part of an include like "limit(5|1):order(created_at|desc).author",
+// returns the parsed modifiers and the sub relations following them.
+func parseModifiers(str string) (P, string, error) {
+	params := P{}
+
+	for {
+		i := 0
+		for i < len(str) && isModifierChar(str[i]) {
+			i++
+		}
+
+		if i == 0 {
+			return nil, "", errors.New("missing modifier name")
+		}
+
+		name := str[:i]
+		if params.Has(name) {
+			return nil, "", fmt.Errorf("duplicate modifier \"%s\"", name)
+		}
+
+		str = str[i:]
+		args := []string{}
+
+		if strings.HasPrefix(str, "(") {
+			end := strings.Index(str, ")")
+			if end < 0 {
+				return nil, "", fmt.Errorf("unclosed parenthesis of modifier \"%s\"", name)
+			}
+
+			if end > 1 {
+				args = strings.Split(str[1:end], "|")
+			}
+
+			str = str[end+1:]
+		}
+
+		params[name] = args
+
+		if str == "" {
+			return params, "", nil
+		}
+
+		switch str[0] {
+		case ':':
+			str = str[1:]
+		case '.':
+			if !isValidIncludeName(str[1:]) {
+				return nil, "", errors.New("empty include segment")
+			}
+			return params, str[1:], nil
+		default:
+			return nil, "", fmt.Errorf("unexpected character %q after modifier \"%s\"", str[0], name)
+		}
+	}
+}
+
+func isModifierChar(c byte) bool {
+	return c == '_' || (c >= '0' && c <= '9') || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+func isValidIncludeName(name string) bool {
+	for _, segment := range strings.Split(name, ".") {
+		if segment == "" {
+			return false
+		}
+	}
+	return true
+}
+
+// Check the wildcards of the include name, returns the reason if they are misplaced
+func checkWildcard(name string) string {
+	segments := strings.Split(name, ".")
+
+	for i, segment := range segments {
+		if !strings.Contains(segment, WildcardInclude) {
 			continue
 		}
-		m.requestedIncludes = append(m.requestedIncludes, includeName)
 
-		// No params
-		if allModifiersStr == "" {
+		if segment != WildcardInclude {
+			return "a wildcard should be a whole include segment"
+		}
+
+		if i != len(segments)-1 {
+			return "a wildcard should be the last include segment"
+		}
+	}
+
+	return ""
+}
+
+func isWildcardInclude(name string) bool {
+	return name == WildcardInclude || strings.HasSuffix(name, "."+WildcardInclude)
+}
+
+// ParseExcludes parse exclude params, an excluded include like "author.books" is dropped
+// from the output even if it is one of the default includes of the transformer. Excludes
+// beyond the recursion limit are ignored, as nothing that deep is included anyway.
+// Malformed excludes are skipped and the first syntax error is returned.
+func (m *Manager) ParseExcludes(excludes []string) error {
+	// Wipe these before we go again
+	m.requestedExcludes = []string{}
+
+	var err error
+
+	for _, exclude := range excludes {
+		exclude = strings.TrimSpace(exclude)
+		if exclude == "" {
 			continue
 		}
 
-		// TODO: parse include params
+		if !isValidIncludeName(exclude) {
+			if err == nil {
+				err = &IncludeSyntaxError{Include: exclude, Reason: "empty include segment"}
+			}
+			continue
+		}
+
+		// Nothing beyond the recursion limit is included, trimming the exclude
+		// down would exclude more than it asks for instead
+		if len(strings.Split(exclude, ".")) > m.recursionLimit {
+			continue
+		}
+
+		if m.hasRequestExclude(exclude) {
+			continue
+		}
+		m.requestedExcludes = append(m.requestedExcludes, exclude)
 	}
 
-	m.autoIncludeParents()
-	return m
+	return err
+}
+
+// ParseFieldsets parse sparse fieldsets keyed by resource type, the fields of a type
+// are separated by ",", e.g. {"books": "title,year"} from "fields[books]=title,year".
+// An empty fieldset like "fields[books]=" keeps the mandatory fields of the serializer only.
+// Types with an empty name are skipped and an error is returned.
+func (m *Manager) ParseFieldsets(fieldsets map[string]string) error {
+	// Wipe these before we go again
+	m.requestedFieldsets = map[string][]string{}
+
+	var err error
+
+	for fieldType, fields := range fieldsets {
+		fieldType = strings.TrimSpace(fieldType)
+		if fieldType == "" {
+			if err == nil {
+				err = errors.New("fieldset type should not be empty")
+			}
+			continue
+		}
+
+		// Remove empty and repeated fields
+		parsed := []string{}
+		for _, field := range strings.Split(fields, ",") {
+			field = strings.TrimSpace(field)
+			if field != "" && !containsString(parsed, field) {
+				parsed = append(parsed, field)
+			}
+		}
+
+		m.requestedFieldsets[fieldType] = parsed
+	}
+
+	return err
 }
 
 func (m *Manager) explodeInclude(include string, sep string) (string, string) {
@@ -70,17 +327,22 @@ func (m *Manager) explodeInclude(include string, sep string) (string, string) {
 func (m *Manager) autoIncludeParents() {
 
 	parsed := []string{}
+	seen := map[string]bool{}
 
 	for _, include := range m.requestedIncludes {
 		nested := strings.Split(include, ".")
-		part := nested[0]
-		parsed = append(parsed, part)
-		nested = nested[1:]
-
-		for len(nested) > 0 {
-			part += "." + nested[0]
-			parsed = append(parsed, part)
-			nested = nested[1:]
+		part := ""
+
+		for i, segment := range nested {
+			if i > 0 {
+				part += "."
+			}
+			part += segment
+
+			if !seen[part] {
+				seen[part] = true
+				parsed = append(parsed, part)
+			}
 		}
 	}
 
@@ -105,6 +367,98 @@ func (m *Manager) SetRecursionLimit(limit int) *Manager {
 	return m
 }
 
+// SetConcurrency set the number of workers transforming the items of every
+// collection concurrently, the items are transformed one by one if n <= 1.
+// The order of the items is preserved and the transformation stops at the
+// first failed item. Streamed collections are always transformed one by one.
+//
+// Only the collections of ContextTransformers are transformed concurrently, the
+// current scope of any other transformer is shared by all the items, so they are
+// transformed one by one. The transformers and includers should be safe for
+// concurrent use, and the transformers of the includes should be ContextTransformers
+// as well unless every item includes its resources with transformers of its own.
+func (m *Manager) SetConcurrency(n int) *Manager {
+	m.concurrency = n
+	return m
+}
+
+// GetConcurrency get the number of workers transforming the items of a collection
+func (m *Manager) GetConcurrency() int {
+	return m.concurrency
+}
+
+// SetIncludeCache enable or disable the include cache, which is disabled by default.
+// Within a single render, the same entity included at the same include path more than
+// once is transformed only once and the included data is reused, entities are identified
+// by EntityIdentifier transformers or by their pointers. The reused data is shared by
+// all the places it is included at, so it should not be modified.
+//
+// The transformers of the cached includes should only depend on the included entity,
+// the output of an include transformed by the data of its parent item is reused for
+// the other parents of the same entity.
+func (m *Manager) SetIncludeCache(enabled bool) *Manager {
+	m.includeCacheEnabled = enabled
+	return m
+}
+
+// IsIncludeCacheEnabled check if the include cache is enabled
+func (m *Manager) IsIncludeCacheEnabled() bool {
+	return m.includeCacheEnabled
+}
+
+// SetDeprecatedIncludeHandler set the handler called when an include is requested by a
+// deprecated alias, e.g. to log a warning or to set a response header. It is called once
+// per include path within a render, and may be called concurrently if SetConcurrency is set.
+func (m *Manager) SetDeprecatedIncludeHandler(handler DeprecatedIncludeHandler) *Manager {
+	m.deprecatedIncludeHandler = handler
+	return m
+}
+
+// SetStrictIncludes enable or disable the strict mode, in which a render fails with an
+// UnknownIncludesError if an include is requested but offered by no transformer at its
+// level. The includes below an include which is never rendered, e.g. as it is null, can
+// not be validated. The error is returned once the render is done, after the items of a
+// collection are written by Scope.WriteJSON.
+func (m *Manager) SetStrictIncludes(strict bool) *Manager {
+	m.strictIncludes = strict
+	return m
+}
+
+// IsStrictIncludes check if the requested includes are validated
+func (m *Manager) IsStrictIncludes() bool {
+	return m.strictIncludes
+}
+
+// SetIncludeAuthorizer set the authorizer deciding if the principal of the scope is allowed
+// to see an include, it is called for the requested and the default includes of every item.
+func (m *Manager) SetIncludeAuthorizer(authorizer IncludeAuthorizer) *Manager {
+	m.includeAuthorizer = authorizer
+	return m
+}
+
+// SetFieldAuthorizer set the authorizer deciding if the principal of the scope is allowed
+// to see a field, it is called for every field of every transformed item.
+func (m *Manager) SetFieldAuthorizer(authorizer FieldAuthorizer) *Manager {
+	m.fieldAuthorizer = authorizer
+	return m
+}
+
+// SetIncludeMeta enable or disable publishing the available and default includes of every
+// scope with includes into its meta, e.g. {"include": {"available": [...], "default": [...]}},
+// so the clients can discover the includes they may request. The includes are listed by
+// their public names, without the deprecated aliases and the ones the include authorizer
+// does not allow. They are merged into the "include" meta set on the resource if it is
+// an M, whose values are kept, and are not published if it is of any other type.
+func (m *Manager) SetIncludeMeta(enabled bool) *Manager {
+	m.includeMeta = enabled
+	return m
+}
+
+// IsIncludeMetaEnabled check if the includes are published into the meta
+func (m *Manager) IsIncludeMetaEnabled() bool {
+	return m.includeMeta
+}
+
 // GetSerializer get data serializer and
 // return DataArraySerializer if no serializer set
 func (m *Manager) GetSerializer() Serializer {
@@ -138,6 +492,13 @@ func (m *Manager) GetFieldset(fieldType string) (fieldset []string) {
 	return
 }
 
+// HasFieldset check if a fieldset is requested for the specified type, an empty
+// fieldset is requested as well, in which case only the mandatory fields are kept.
+func (m *Manager) HasFieldset(fieldType string) bool {
+	_, ok := m.requestedFieldsets[fieldType]
+	return ok
+}
+
 // GetRequestedIncludes get requested includes
 func (m *Manager) GetRequestedIncludes() []string {
 	return m.requestedIncludes
@@ -152,7 +513,16 @@ func (m *Manager) hasRequestInclude(include string) bool {
 	return false
 }
 
-// GetIncludeParams get include params
+func (m *Manager) hasRequestExclude(exclude string) bool {
+	for _, e := range m.requestedExcludes {
+		if e == exclude {
+			return true
+		}
+	}
+	return false
+}
+
+// GetIncludeParams get include params by the full include path, e.g. "author.comments"
 func (m *Manager) GetIncludeParams(identifier string) P {
 	if m.includeParams == nil {
 		return nil
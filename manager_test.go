@@ -37,4 +37,139 @@ func TestParseIncludes(t *testing.T) {
 
 		assert.Equal(t, expected, actual)
 	})
+
+	t.Run("wildcard", func(t *testing.T) {
+		manager.SetRecursionLimit(2)
+		err := manager.ParseIncludesE([]string{"*", "author.*", "author.books.*"})
+
+		assert.Nil(t, err)
+		assert.Equal(t, []string{"*", "author", "author.*", "author.books"}, manager.GetRequestedIncludes())
+	})
+}
+
+func TestParseIncludeParams(t *testing.T) {
+	manager := fractal.NewManager(nil)
+
+	t.Run("modifiers", func(t *testing.T) {
+		err := manager.ParseIncludesE([]string{"author.comments:limit(5|1):order(created_at|desc)"})
+
+		assert.Nil(t, err)
+		assert.Equal(t, []string{"author", "author.comments"}, manager.GetRequestedIncludes())
+		assert.Equal(t, fractal.P{
+			"limit": {"5", "1"},
+			"order": {"created_at", "desc"},
+		}, manager.GetIncludeParams("author.comments"))
+		assert.Nil(t, manager.GetIncludeParams("author"))
+	})
+
+	t.Run("modifier without arguments", func(t *testing.T) {
+		err := manager.ParseIncludesE([]string{"comments:sorted"})
+		params := manager.GetIncludeParams("comments")
+
+		assert.Nil(t, err)
+		assert.True(t, params.Has("sorted"))
+		assert.Empty(t, params.Get("sorted"))
+		assert.Equal(t, "", params.First("sorted"))
+	})
+
+	t.Run("sub relations", func(t *testing.T) {
+		err := manager.ParseIncludesE([]string{"comments:limit(5).author:fields(name)"})
+
+		assert.Nil(t, err)
+		assert.Equal(t, []string{"comments", "comments.author"}, manager.GetRequestedIncludes())
+		assert.Equal(t, "5", manager.GetIncludeParams("comments").First("limit"))
+		assert.Equal(t, "name", manager.GetIncludeParams("comments.author").First("fields"))
+	})
+
+	t.Run("duplicate includes", func(t *testing.T) {
+		err := manager.ParseIncludesE([]string{"comments:limit(5)", "comments:order(id|desc)", "comments"})
+
+		assert.Nil(t, err)
+		assert.Equal(t, []string{"comments"}, manager.GetRequestedIncludes())
+		assert.Equal(t, fractal.P{"limit": {"5"}, "order": {"id", "desc"}}, manager.GetIncludeParams("comments"))
+
+		err = manager.ParseIncludesE([]string{"comments:limit(5)", "comments:limit(1)"})
+
+		assert.EqualError(t, err, "malformed include \"comments:limit(1)\": duplicate modifier \"limit\"")
+		assert.Equal(t, "5", manager.GetIncludeParams("comments").First("limit"))
+	})
+
+	t.Run("chaining", func(t *testing.T) {
+		actual := manager.ParseIncludes([]string{"comments:limit(5", "author"}).GetRequestedIncludes()
+
+		assert.Equal(t, []string{"author"}, actual)
+	})
+
+	t.Run("malformed", func(t *testing.T) {
+		for _, include := range []string{
+			"comments:limit(5",
+			"comments:",
+			"comments:limit(5):limit(1)",
+			"comments:limit(5)order(id)",
+			"comments..author",
+			"comments:limit(5).",
+			"*:limit(5)",
+			"*.author",
+			"comments*",
+		} {
+			err := manager.ParseIncludesE([]string{include, "author"})
+
+			assert.IsType(t, &fractal.IncludeSyntaxError{}, err, include)
+			assert.Equal(t, []string{"author"}, manager.GetRequestedIncludes(), include)
+		}
+	})
+}
+
+func TestParseExcludes(t *testing.T) {
+	manager := fractal.NewManager(nil)
+
+	t.Run("default", func(t *testing.T) {
+		err := manager.ParseExcludes([]string{"author", "author.books", "author", ""})
+
+		assert.Nil(t, err)
+		assert.Equal(t, []string{"author", "author.books"}, manager.GetRequestedExcludes())
+	})
+
+	t.Run("recursion limit", func(t *testing.T) {
+		manager.SetRecursionLimit(2)
+		err := manager.ParseExcludes([]string{"one.two.three", "one.two"})
+
+		assert.Nil(t, err)
+		assert.Equal(t, []string{"one.two"}, manager.GetRequestedExcludes())
+
+		err = manager.ParseExcludes([]string{"one.two.three"})
+
+		assert.Nil(t, err)
+		assert.Empty(t, manager.GetRequestedExcludes())
+	})
+
+	t.Run("malformed", func(t *testing.T) {
+		err := manager.ParseExcludes([]string{"author..books", "books"})
+
+		assert.IsType(t, &fractal.IncludeSyntaxError{}, err)
+		assert.Equal(t, []string{"books"}, manager.GetRequestedExcludes())
+	})
+}
+
+func TestParseFieldsets(t *testing.T) {
+	manager := fractal.NewManager(nil)
+
+	t.Run("default", func(t *testing.T) {
+		err := manager.ParseFieldsets(map[string]string{
+			"books":   "title, year,,title",
+			"authors": "",
+		})
+
+		assert.Nil(t, err)
+		assert.Equal(t, []string{"title", "year"}, manager.GetFieldset("books"))
+		assert.Empty(t, manager.GetFieldset("authors"))
+		assert.Nil(t, manager.GetFieldset("users"))
+	})
+
+	t.Run("empty type", func(t *testing.T) {
+		err := manager.ParseFieldsets(map[string]string{"": "title"})
+
+		assert.NotNil(t, err)
+		assert.Empty(t, manager.GetRequestedFieldsets())
+	})
 }
@@ -0,0 +1,176 @@
+package fractal
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+// includeMethod a method including a resource, e.g. IncludeAuthor for the include "author"
+type includeMethod struct {
+	index       int
+	withContext bool
+	withError   bool
+}
+
+// includeMethods the include methods of a type by their method names
+type includeMethods map[string]*includeMethod
+
+var includeMethodsCache sync.Map
+
+var (
+	anyType              = reflect.TypeOf((*Any)(nil)).Elem()
+	paramsType           = reflect.TypeOf(P{})
+	resourceType         = reflect.TypeOf((*Resource)(nil)).Elem()
+	errorType            = reflect.TypeOf((*error)(nil)).Elem()
+	transformContextType = reflect.TypeOf(&TransformContext{})
+)
+
+// methodIncluder dispatches the includes to the include methods of the includer
+type methodIncluder struct {
+	value   reflect.Value
+	methods includeMethods
+}
+
+// IncludeWithContext call the include method of the include, the includes without
+// include methods include nothing.
+func (i *methodIncluder) IncludeWithContext(ctx *TransformContext, includeName string, data Any, params P) (Resource, error) {
+	method, ok := i.methods[includeMethodName(includeName)]
+	if !ok {
+		return nil, nil
+	}
+
+	args := []reflect.Value{reflect.ValueOf(&data).Elem(), reflect.ValueOf(params)}
+	if method.withContext {
+		args = append([]reflect.Value{reflect.ValueOf(ctx)}, args...)
+	}
+
+	out := i.value.Method(method.index).Call(args)
+
+	resource, _ := out[0].Interface().(Resource)
+	if method.withError {
+		err, _ := out[1].Interface().(error)
+		return resource, err
+	}
+
+	return resource, nil
+}
+
+// SetMethodIncluder set the includer which includes the resources by its include methods,
+// the include "author" is included by the method IncludeAuthor and "blog_posts" by
+// IncludeBlogPosts. An include method is one of the forms
+//
+//	func(data Any, params P) Resource
+//	func(data Any, params P) (Resource, error)
+//	func(ctx *TransformContext, data Any, params P) Resource
+//	func(ctx *TransformContext, data Any, params P) (Resource, error)
+//
+// The available and default includes should be set first, an error is returned if any of
+// them has no include method, in which case the includer is left unchanged.
+func (t *BaseTransformer) SetMethodIncluder(includer Any) error {
+	typ := reflect.TypeOf(includer)
+	if typ == nil {
+		return fmt.Errorf("the method includer should not be nil")
+	}
+
+	methods := getIncludeMethods(typ)
+	missing := []string{}
+
+	for _, include := range append(append([]string{}, t.GetAvailableIncludes()...), t.GetDefaultIncludes()...) {
+		name := includeMethodName(include)
+		if _, ok := methods[name]; ok {
+			continue
+		}
+
+		if m, ok := typ.MethodByName(name); ok {
+			return fmt.Errorf("the method %s of %s for the include \"%s\" has an invalid signature %s", name, typ, include, m.Type)
+		}
+
+		if !containsString(missing, name) {
+			missing = append(missing, name)
+		}
+	}
+
+	if len(missing) > 0 {
+		return fmt.Errorf("the includer %s has no include methods %s", typ, strings.Join(missing, ", "))
+	}
+
+	t.includer = &methodIncluder{value: reflect.ValueOf(includer), methods: methods}
+	t.batchIncluder, _ = includer.(BatchIncluder)
+	return nil
+}
+
+// Get the name of the include method of the include, e.g. IncludeBlogPosts for "blog_posts"
+func includeMethodName(include string) string {
+	var b strings.Builder
+	b.WriteString("Include")
+
+	for _, word := range strings.FieldsFunc(include, func(r rune) bool { return r == '_' || r == '-' }) {
+		runes := []rune(word)
+		runes[0] = unicode.ToUpper(runes[0])
+		b.WriteString(string(runes))
+	}
+
+	return b.String()
+}
+
+// Get the include methods of the type, which are cached by the type
+func getIncludeMethods(typ reflect.Type) includeMethods {
+	if methods, ok := includeMethodsCache.Load(typ); ok {
+		return methods.(includeMethods)
+	}
+
+	methods := includeMethods{}
+
+	for i := 0; i < typ.NumMethod(); i++ {
+		m := typ.Method(i)
+		if !strings.HasPrefix(m.Name, "Include") {
+			continue
+		}
+
+		if method, ok := toIncludeMethod(m); ok {
+			methods[m.Name] = method
+		}
+	}
+
+	includeMethodsCache.Store(typ, methods)
+	return methods
+}
+
+// Check the signature of the method, the first argument of which is the receiver
+func toIncludeMethod(m reflect.Method) (*includeMethod, bool) {
+	in := []reflect.Type{}
+	for i := 1; i < m.Type.NumIn(); i++ {
+		in = append(in, m.Type.In(i))
+	}
+
+	method := &includeMethod{index: m.Index}
+
+	if len(in) == 3 && in[0] == transformContextType {
+		method.withContext = true
+		in = in[1:]
+	}
+
+	if len(in) != 2 || in[0] != anyType || in[1] != paramsType || m.Type.IsVariadic() {
+		return nil, false
+	}
+
+	switch m.Type.NumOut() {
+	case 1:
+	case 2:
+		if m.Type.Out(1) != errorType {
+			return nil, false
+		}
+		method.withError = true
+	default:
+		return nil, false
+	}
+
+	if m.Type.Out(0) != resourceType {
+		return nil, false
+	}
+
+	return method, true
+}
@@ -0,0 +1,188 @@
+package pagination
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"sort"
+	"strings"
+)
+
+// Keyset holds the values of the columns a keyset cursor points at,
+// e.g. Keyset{"created_at": "2021-06-01", "id": 10}
+type Keyset map[string]interface{}
+
+// EncodeKeyset encode the keyset into an opaque cursor,
+// the values of the keyset should be json encodable.
+func EncodeKeyset(keys Keyset) (string, error) {
+	if len(keys) == 0 {
+		return "", nil
+	}
+
+	b, err := json.Marshal(keys)
+	if err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// DecodeKeyset decode the keyset from an opaque cursor,
+// numbers are decoded as json.Number.
+func DecodeKeyset(cursor string) (Keyset, error) {
+	if cursor == "" {
+		return nil, nil
+	}
+
+	b, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, err
+	}
+
+	keys := Keyset{}
+	decoder := json.NewDecoder(bytes.NewReader(b))
+	decoder.UseNumber()
+
+	if err := decoder.Decode(&keys); err != nil {
+		return nil, err
+	}
+
+	return keys, nil
+}
+
+// KeysetCursor cursor based on the keyset of the first and last items of the current page
+type KeysetCursor struct {
+	items      []interface{}
+	current    string
+	prev       Keyset
+	next       Keyset
+	cursorName string
+	path       string
+	query      map[string]string
+}
+
+// GetItems get current items
+func (c *KeysetCursor) GetItems() []interface{} {
+	return c.items
+}
+
+// SetItems set current items
+func (c *KeysetCursor) SetItems(items []interface{}) {
+	c.items = items
+}
+
+// GetCurrent get the cursor of the current items
+func (c *KeysetCursor) GetCurrent() string {
+	return c.current
+}
+
+// GetPrev get the cursor of the previous items, empty if there is none
+func (c *KeysetCursor) GetPrev() string {
+	cursor, _ := EncodeKeyset(c.prev)
+	return cursor
+}
+
+// GetNext get the cursor of the next items, empty if there is none
+func (c *KeysetCursor) GetNext() string {
+	cursor, _ := EncodeKeyset(c.next)
+	return cursor
+}
+
+// GetCount get the number of all items of the current cursor
+func (c *KeysetCursor) GetCount() uint {
+	return uint(len(c.items))
+}
+
+// GetURL get the URL for a given cursor
+func (c *KeysetCursor) GetURL(cursor string) string {
+	query := c.GetPath()
+	sep := "?"
+	if strings.Contains(query, sep) {
+		sep = "&"
+	}
+	query += sep
+
+	keys := make([]string, 0, len(c.query))
+	for k := range c.query {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		query += (k + "=" + c.query[k] + "&")
+	}
+
+	return query + c.cursorName + "=" + cursor
+}
+
+// GetPath get the base path for cursor generated URLs.
+func (c *KeysetCursor) GetPath() string {
+	if c.path == "" {
+		return "/"
+	}
+	return c.path
+}
+
+// SetPath set the base path for cursor generated URLs.
+func (c *KeysetCursor) SetPath(path string) *KeysetCursor {
+	c.path = path
+	return c
+}
+
+// ModKeysetCursor function to modify KeysetCursor
+type ModKeysetCursor func(c *KeysetCursor)
+
+// WithPrevKeys is an easy way to set the keyset of the previous items
+func WithPrevKeys(keys Keyset) ModKeysetCursor {
+	return func(c *KeysetCursor) {
+		c.prev = keys
+	}
+}
+
+// WithNextKeys is an easy way to set the keyset of the next items
+func WithNextKeys(keys Keyset) ModKeysetCursor {
+	return func(c *KeysetCursor) {
+		c.next = keys
+	}
+}
+
+// WithCursorName is an easy way to set the query name of the cursor
+func WithCursorName(name string) ModKeysetCursor {
+	return func(c *KeysetCursor) {
+		c.cursorName = name
+	}
+}
+
+// WithCursorPath is an easy way to set path for cursor
+func WithCursorPath(path string) ModKeysetCursor {
+	return func(c *KeysetCursor) {
+		if path != "/" {
+			path = strings.TrimRight(path, "/")
+		}
+
+		c.path = path
+	}
+}
+
+// WithCursorQuery is an easy way to set query for cursor
+func WithCursorQuery(query map[string]string) ModKeysetCursor {
+	return func(c *KeysetCursor) {
+		c.query = query
+	}
+}
+
+// NewKeysetCursor create KeysetCursor instance, current is the cursor
+// the items were fetched with, e.g. the "cursor" query of the request.
+func NewKeysetCursor(items []interface{}, current string, mods ...ModKeysetCursor) *KeysetCursor {
+	cursor := &KeysetCursor{
+		items:      items,
+		current:    current,
+		cursorName: "cursor",
+	}
+
+	for _, mod := range mods {
+		mod(cursor)
+	}
+
+	return cursor
+}
@@ -0,0 +1,25 @@
+package fractal
+
+// P holds the modifiers requested for an include, each modifier
+// can carry multiple arguments, e.g. "comments:limit(5|1):order(created_at|desc)"
+// is parsed into P{"limit": {"5", "1"}, "order": {"created_at", "desc"}}
+type P map[string][]string
+
+// Get get all arguments of the modifier
+func (p P) Get(modifier string) []string {
+	return p[modifier]
+}
+
+// First get the first argument of the modifier
+func (p P) First(modifier string) string {
+	if args := p[modifier]; len(args) > 0 {
+		return args[0]
+	}
+	return ""
+}
+
+// Has if the modifier is requested
+func (p P) Has(modifier string) bool {
+	_, ok := p[modifier]
+	return ok
+}
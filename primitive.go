@@ -18,6 +18,7 @@ func NewPrimitive(opts ...ModResourceOption) *Primitive {
 		Item: Item{
 			data:        opt.data,
 			resourceKey: opt.resourceKey,
+			links:       opt.links,
 			transformer: opt.transformer,
 		},
 	}
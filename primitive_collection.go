@@ -18,8 +18,10 @@ func NewPrimitiveCollection(opts ...ModResourceOption) *PrimitiveCollection {
 			Item: Item{
 				data:        opt.data,
 				resourceKey: opt.resourceKey,
+				links:       opt.links,
 				transformer: opt.transformer,
 			},
+			mapOrder: opt.mapOrder,
 		},
 	}
 }
@@ -5,6 +5,8 @@ type ResourceOption struct {
 	data        Any
 	resourceKey string
 	transformer Transformer
+	mapOrder    MapOrder
+	links       Links
 }
 
 // ModResourceOption function to modify resource option
@@ -30,3 +32,18 @@ func WithResourceKey(key string) ModResourceOption {
 		option.resourceKey = key
 	}
 }
+
+// WithLinks is an easy way to set links for resource
+func WithLinks(links Links) ModResourceOption {
+	return func(option *ResourceOption) {
+		option.links = links
+	}
+}
+
+// WithMapOrder is an easy way to set the order in which
+// the values of a map data are used as collection items
+func WithMapOrder(order MapOrder) ModResourceOption {
+	return func(option *ResourceOption) {
+		option.mapOrder = order
+	}
+}
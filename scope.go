@@ -1,9 +1,16 @@
 package fractal
 
 import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
 	"encoding/json"
 	"errors"
+	"io"
 	"strings"
+	"sync"
 )
 
 // Scope acts as a tracker, relating a specific resource in a specific
@@ -14,7 +21,12 @@ type Scope struct {
 	manager           *Manager
 	resource          Resource
 	availableIncludes []string
+	defaultIncludes   []string
 	parentScopes      []string
+	ctx               context.Context
+	principal         Any
+	// The state shared by the scopes of the current render
+	render *renderState
 }
 
 // ToJSON convert the current data for this scope to json.
@@ -28,31 +40,343 @@ func (s *Scope) ToJSON() (string, error) {
 	return string(str), err
 }
 
+// WriteJSON write the json of the current data for this scope to the writer. The items
+// of a collection are transformed and written one by one, so a collection pulled from
+// an Iterator is never held in memory as a whole. The output is the same as ToJSON.
+// BatchIncluders include the resources of the items by chunks of the collection.
+//
+// Serializers side-loading the includes need the whole collection, in which case
+// the data is converted by ToMap before it is written. If an item fails to be
+// transformed the error is returned, but the items already written are kept.
+func (s *Scope) WriteJSON(w io.Writer) error {
+	serializer := s.manager.GetSerializer()
+
+	c, ok := s.resource.(*Collection)
+	if !ok || serializer.SideloadIncludes() {
+		return s.writeMap(w)
+	}
+
+	end := s.beginRender()
+	return end(s.streamJSON(w, c, serializer))
+}
+
+// Write the json of the collection to the writer, the items are transformed one by one
+func (s *Scope) streamJSON(w io.Writer, c *Collection, serializer Serializer) error {
+	transformer := s.resource.GetTransformer()
+	s.setAvailableIncludes(transformer)
+
+	// Serialize the document with a placeholder for the items and split it around
+	// the placeholder, serializers reshaping the items can not be streamed.
+	placeholder, err := newStreamPlaceholder()
+	if err != nil {
+		return err
+	}
+
+	document, err := json.Marshal(s.serializeEnvelope(serializer, s.serializeResource(serializer, placeholder)))
+	if err != nil {
+		return err
+	}
+
+	parts := bytes.Split(document, placeholder.token)
+	if len(parts) != 2 {
+		return s.writeMap(w)
+	}
+
+	buf := bufio.NewWriter(w)
+	buf.Write(parts[0])
+	buf.WriteByte('[')
+
+	first := true
+	err = s.eachItem(c, func(transformed, included M) error {
+		item, err := json.Marshal(transformed)
+		if err != nil {
+			return err
+		}
+
+		if !first {
+			buf.WriteByte(',')
+		}
+		first = false
+
+		_, err = buf.Write(item)
+		return err
+	})
+
+	if err != nil {
+		buf.Flush()
+		return err
+	}
+
+	buf.WriteByte(']')
+	buf.Write(parts[1])
+	return buf.Flush()
+}
+
+// WriteNDJSON write the current data for this scope to the writer as newline delimited
+// json, every item of a collection is transformed along with its includes and written
+// as a line of its own without the wrapper of the serializer. With meta, the meta and
+// the pagination are written as a trailing line if there are any.
+//
+// The includes are embedded into the lines even if the serializer side-loads them.
+// If an item fails to be transformed the error is returned, but the lines already
+// written are kept.
+func (s *Scope) WriteNDJSON(w io.Writer, withMeta bool) error {
+	end := s.beginRender()
+	return end(s.writeNDJSON(w, withMeta))
+}
+
+func (s *Scope) writeNDJSON(w io.Writer, withMeta bool) error {
+	serializer := s.manager.GetSerializer()
+	buf := bufio.NewWriter(w)
+
+	writeLine := func(transformed, included M) error {
+		if serializer.SideloadIncludes() {
+			for k, v := range included {
+				transformed[k] = v
+			}
+		}
+
+		line, err := json.Marshal(transformed)
+		if err != nil {
+			return err
+		}
+
+		buf.Write(line)
+		return buf.WriteByte('\n')
+	}
+
+	var err error
+	transformer := s.resource.GetTransformer()
+
+	switch r := s.resource.(type) {
+	case *Item:
+		var transformed, included M
+		s.setAvailableIncludes(transformer)
+		if transformed, included, err = s.fireTransformer(newTransformContext(s), transformer, r.GetData()); err != nil {
+			err = wrapScopeError(s.GetIdentifier(""), err)
+		} else {
+			err = writeLine(transformed, included)
+		}
+	case *Collection:
+		err = s.eachItem(r, writeLine)
+	default:
+		err = errors.New("argument resource should be an instance of fractal.Item or fractal.Collection")
+	}
+
+	if err == nil && withMeta {
+		if meta := s.serializeEnvelope(serializer, nil); len(meta) > 0 {
+			err = writeLine(meta, nil)
+		}
+	}
+
+	if err != nil {
+		buf.Flush()
+		return err
+	}
+
+	return buf.Flush()
+}
+
+// streamChunkSize the number of items of a streamed collection whose resources
+// are included at once if the includer of the transformer is a BatchIncluder
+const streamChunkSize = 100
+
+// Transform the items of the collection one by one and pass them along with their
+// included data to the callback, the iteration stops at the first error. If the
+// includer of the transformer is a BatchIncluder, the items are pulled in chunks
+// and the resources of every chunk are included at once.
+func (s *Scope) eachItem(c *Collection, fn func(transformed, included M) error) error {
+	items, err := newIterator(c.GetData(), c.GetMapOrder())
+	if err != nil {
+		return wrapScopeError(s.GetIdentifier(""), err)
+	}
+	defer closeIterator(items)
+
+	transformer := c.GetTransformer()
+	s.setAvailableIncludes(transformer)
+
+	preloader, batched := transformer.(batchPreloader)
+	batched = batched && preloader.canPreloadIncludes()
+
+	chunk := []Any{}
+	flush := func() error {
+		var batch includeBatch
+		if batched && len(chunk) > 0 {
+			var err error
+			if batch, err = preloader.preloadIncludes(newTransformContext(s), chunk); err != nil {
+				return wrapScopeError(s.GetIdentifier(""), err)
+			}
+		}
+
+		for i, data := range chunk {
+			if err := s.Context().Err(); err != nil {
+				return wrapScopeError(s.GetIdentifier(""), err)
+			}
+
+			ctx := newTransformContext(s)
+			ctx.batch, ctx.index = batch, i
+
+			transformed, included, err := s.fireTransformer(ctx, transformer, data)
+			if err != nil {
+				return wrapScopeError(s.GetIdentifier(""), err)
+			}
+
+			if err := fn(transformed, included); err != nil {
+				return err
+			}
+		}
+
+		chunk = []Any{}
+		return nil
+	}
+
+	for items.Next() {
+		chunk = append(chunk, items.Value())
+
+		if !batched || len(chunk) == streamChunkSize {
+			if err := flush(); err != nil {
+				return err
+			}
+		}
+	}
+
+	if err := items.Err(); err != nil {
+		return wrapScopeError(s.GetIdentifier(""), err)
+	}
+
+	return flush()
+}
+
+// Write the json of the data converted by ToMap to the writer
+func (s *Scope) writeMap(w io.Writer) error {
+	m, err := s.ToMap()
+	if err != nil {
+		return err
+	}
+
+	b, err := json.Marshal(m)
+	if err != nil {
+		return err
+	}
+
+	_, err = w.Write(b)
+	return err
+}
+
+// streamPlaceholder stands for the items of a streamed collection
+// while the document around them is serialized.
+type streamPlaceholder struct {
+	token []byte
+}
+
+func newStreamPlaceholder() (*streamPlaceholder, error) {
+	nonce := make([]byte, 16)
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+
+	return &streamPlaceholder{token: []byte(`"fractal-stream-` + hex.EncodeToString(nonce) + `"`)}, nil
+}
+
+// MarshalJSON marshal the placeholder as its unique token
+func (p *streamPlaceholder) MarshalJSON() ([]byte, error) {
+	return p.token, nil
+}
+
+// ToMapContext convert the current data for this scope to a map in the context, which
+// is passed to the transformers and the includers along with the child scopes.
+// The transformation stops with the error of the context once it is done.
+func (s *Scope) ToMapContext(ctx context.Context) (M, error) {
+	scope := *s
+	scope.ctx = ctx
+	return scope.ToMap()
+}
+
 // ToMap convert the current data for this scope to a map.
 func (s *Scope) ToMap() (M, error) {
+	end := s.beginRender()
 
-	rawData, _, err := s.executeResourceTransformers()
-	if err != nil {
+	data, err := s.toMap()
+	if err = end(err); err != nil {
 		return nil, err
 	}
 
+	return data, nil
+}
+
+func (s *Scope) toMap() (M, error) {
+	rawData, rawIncludedData, err := s.executeResourceTransformers()
+	if err != nil {
+		return nil, wrapScopeError(s.GetIdentifier(""), err)
+	}
+
 	serializer := s.manager.GetSerializer()
 	data := s.serializeResource(serializer, rawData)
 
 	// If the serializer wants the includes to be side-loaded then we'll
 	// serialize the included data and merge it with the data.
-	// if serializer.SideloadIncludes() {
-	// 	//
-	// }
+	if serializer.SideloadIncludes() {
+		// Filter out any relation that wasn't requested
+		for i, included := range rawIncludedData {
+			rawIncludedData[i] = s.filterFieldsets(included)
+		}
+
+		includedData := serializer.IncludeData(s.resource, rawIncludedData)
+
+		// If the serializer wants to inject additional information
+		// about the included resources, it can do so now.
+		if injected, ok := serializer.InjectData(data, rawIncludedData).(M); ok {
+			data = injected
+		}
+
+		if s.isRootScope() {
+			// If the serializer wants to have a final word about all
+			// the objects that are sideloaded, it can do so now.
+			includedData = serializer.FilterIncludes(includedData, data)
+		}
+
+		if included, ok := includedData.(M); ok && len(included) > 0 {
+			if data == nil {
+				data = M{}
+			}
+
+			for k, v := range included {
+				data[k] = v
+			}
+		}
+	}
 
+	return s.serializeEnvelope(serializer, data), nil
+}
+
+// Serialize the available includes, the pagination, the meta and
+// the links of the resource around the serialized data.
+func (s *Scope) serializeEnvelope(serializer Serializer, data M) M {
 	if len(s.availableIncludes) > 0 {
 		data = serializer.InjectAvailableIncludeData(data, s.availableIncludes)
 	}
 
+	if s.manager.IsIncludeMetaEnabled() && (len(s.availableIncludes) > 0 || len(s.defaultIncludes) > 0) {
+		include := M{
+			"available": s.authorizedIncludes(s.availableIncludes),
+			"default":   s.authorizedIncludes(s.defaultIncludes),
+		}
+
+		// The include meta set on the resource is kept, its values win over ours
+		switch existing := s.resource.GetMetaValue("include").(type) {
+		case nil:
+			s.resource.SetMetaValue("include", include)
+		case M:
+			s.resource.SetMetaValue("include", mergeMaps(include, existing))
+		}
+	}
+
 	if c, ok := s.resource.(*Collection); ok {
 		var pagination M
 
-		if c.HasPaginator() {
+		if c.HasCursor() {
+			pagination = serializer.Cursor(c.GetCursor())
+		} else if c.HasPaginator() {
 			pagination = serializer.Paginator(c.GetPaginator())
 		}
 
@@ -62,76 +386,140 @@ func (s *Scope) ToMap() (M, error) {
 	}
 
 	meta := serializer.Meta(s.resource.GetMeta())
+	if resource, ok := s.resource.(LinkedResource); ok && len(resource.GetLinks()) > 0 {
+		if linkSerializer, ok := serializer.(LinkSerializer); ok {
+			meta = mergeMaps(meta, linkSerializer.Links(resource.GetLinks()))
+		}
+	}
+
 	if data == nil {
 		if len(meta) != 0 {
-			return meta, nil
+			return meta
 		}
 
-		return nil, nil
+		return nil
 	}
 
-	for k, v := range meta {
-		data[k] = v
+	return mergeMaps(data, meta)
+}
+
+// Merge the src into the dst, values which are maps in both are merged as well,
+// e.g. the links of the resource and the pagination links of the meta.
+func mergeMaps(dst, src M) M {
+	if dst == nil && len(src) > 0 {
+		dst = M{}
 	}
 
-	return data, nil
+	for k, v := range src {
+		d, ok1 := dst[k].(M)
+		s, ok2 := v.(M)
+
+		if ok1 && ok2 {
+			v = mergeMaps(mergeMaps(nil, d), s)
+		}
+
+		dst[k] = v
+	}
+
+	return dst
 }
 
 // TransformPrimitiveResource transformer a primitive resource
 func (s *Scope) TransformPrimitiveResource() (Any, error) {
+	end := s.beginRender()
+
+	data, err := s.transformPrimitiveResource()
+	if err = end(wrapScopeError(s.GetIdentifier(""), err)); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+func (s *Scope) transformPrimitiveResource() (Any, error) {
 	transformer := s.resource.GetTransformer()
-	transformer.SetCurrentScope(s)
 	data := s.resource.GetData()
 
-	switch s.resource.(type) {
+	// Primitives include nothing
+	s.offerIncludes(nil)
+
+	switch r := s.resource.(type) {
 	case *Primitive:
-		data = transformer.Transform(data)
-		return data, nil
+		return s.transform(newTransformContext(s), transformer, data)
 	case *PrimitiveCollection:
 		transformedData := []Any{}
-		anyCollection, ok := data.([]Any)
-
-		if !ok {
-			return nil, errors.New(
-				"the data of primitive collection resource should be []interface{} or []Any",
-			)
+		items, err := newIterator(data, r.GetMapOrder())
+		if err != nil {
+			return nil, err
 		}
+		defer closeIterator(items)
 
-		for _, d := range anyCollection {
-			transformedData = append(transformedData, transformer.Transform(d))
+		for items.Next() {
+			transformed, err := s.transform(newTransformContext(s), transformer, items.Value())
+			if err != nil {
+				return nil, err
+			}
+			transformedData = append(transformedData, transformed)
 		}
 
-		return transformedData, nil
+		return transformedData, items.Err()
 	}
 
 	return nil, errors.New("argument resource should be an instance of fractal.Primitive or fractal.PrimitiveCollection")
 }
 
-func (s *Scope) executeResourceTransformers() (Any, Any, error) {
+// Execute the resources transformer and return the transformed data and
+// the included data of every single item.
+func (s *Scope) executeResourceTransformers() (Any, []M, error) {
 	transformer := s.resource.GetTransformer()
 	data := s.resource.GetData()
+	s.setAvailableIncludes(transformer)
 
-	switch s.resource.(type) {
+	switch r := s.resource.(type) {
 	case *Item:
-		transformedData, includedData := s.fireTransformer(transformer, data)
-		return transformedData, includedData, nil
+		transformedData, includedData, err := s.fireTransformer(newTransformContext(s), transformer, data)
+		if err != nil {
+			return nil, nil, err
+		}
+		return transformedData, []M{includedData}, nil
 	case *Collection:
 		transformedData := []Any{}
-		includedData := []Any{}
-		anyCollection, ok := data.([]Any)
+		includedData := []M{}
+		items, err := newIterator(data, r.GetMapOrder())
+		if err != nil {
+			return nil, nil, err
+		}
+		defer closeIterator(items)
 
-		if !ok {
-			return nil, nil, errors.New(
-				"the data of collection resource should be []interface{} or []Any",
-			)
+		var batch includeBatch
+		if items, batch, err = s.preloadIncludes(transformer, items); err != nil {
+			return nil, nil, err
 		}
 
-		for _, d := range anyCollection {
-			transformed, included := s.fireTransformer(transformer, d)
+		// The current scope of the other transformers is shared by all the items
+		if _, ok := transformer.(ContextTransformer); ok && s.manager.GetConcurrency() > 1 {
+			return s.transformConcurrently(transformer, items, batch, s.manager.GetConcurrency())
+		}
+
+		for i := 0; items.Next(); i++ {
+			if err := s.Context().Err(); err != nil {
+				return nil, nil, err
+			}
+
+			ctx := newTransformContext(s)
+			ctx.batch, ctx.index = batch, i
+
+			transformed, included, err := s.fireTransformer(ctx, transformer, items.Value())
+			if err != nil {
+				return nil, nil, err
+			}
 			transformedData = append(transformedData, transformed)
 			includedData = append(includedData, included)
 		}
 
+		if err := items.Err(); err != nil {
+			return nil, nil, err
+		}
+
 		return transformedData, includedData, nil
 	}
 
@@ -140,6 +528,147 @@ func (s *Scope) executeResourceTransformers() (Any, Any, error) {
 	)
 }
 
+// Include the resources of all the items of the collection at once if the includer
+// of the transformer is a BatchIncluder, the items are pulled from the iterator
+// before they are transformed, and an iterator over the pulled items is returned.
+func (s *Scope) preloadIncludes(transformer Transformer, items Iterator) (Iterator, includeBatch, error) {
+	preloader, ok := transformer.(batchPreloader)
+	if !ok || !preloader.canPreloadIncludes() {
+		return items, nil, nil
+	}
+
+	all := []Any{}
+	for items.Next() {
+		all = append(all, items.Value())
+	}
+
+	if err := items.Err(); err != nil {
+		return nil, nil, err
+	}
+
+	batch, err := preloader.preloadIncludes(newTransformContext(s), all)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return &sliceIterator{items: all, index: -1}, batch, nil
+}
+
+// transformJob an item of a collection transformed by a worker
+type transformJob struct {
+	index       int
+	data        Any
+	transformed M
+	included    M
+}
+
+// Transform the items of the collection by n workers, the items are pulled from the
+// iterator one by one and the order of them is preserved. No more items are pulled
+// once an item fails or the context of the scope is done, and the first error
+// happened is returned. A panic of a worker is recovered and panics again on the
+// calling goroutine once all the workers are done, as if the items were transformed
+// one by one.
+func (s *Scope) transformConcurrently(transformer Transformer, items Iterator, batch includeBatch, n int) (Any, []M, error) {
+	ctx, cancel := context.WithCancel(s.Context())
+	defer cancel()
+
+	// The default serializer is set lazily, make sure it is set before the workers start
+	s.manager.GetSerializer()
+
+	// The items are transformed in the cancelable context, so the
+	// transformations still running stop once an item fails.
+	scope := *s
+	scope.ctx = ctx
+
+	var (
+		wg         sync.WaitGroup
+		once       sync.Once
+		firstErr   error
+		panicOnce  sync.Once
+		panicked   bool
+		firstPanic Any
+		jobs       = make(chan *transformJob)
+	)
+
+	transform := func(job *transformJob) {
+		defer func() {
+			if r := recover(); r != nil {
+				panicOnce.Do(func() {
+					panicked, firstPanic = true, r
+					cancel()
+				})
+			}
+		}()
+
+		ctx := newTransformContext(&scope)
+		ctx.batch, ctx.index = batch, job.index
+
+		transformed, included, err := scope.fireTransformer(ctx, transformer, job.data)
+		if err != nil {
+			once.Do(func() {
+				firstErr = err
+				cancel()
+			})
+			return
+		}
+		job.transformed, job.included = transformed, included
+	}
+
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			for job := range jobs {
+				transform(job)
+			}
+		}()
+	}
+
+	all := []*transformJob{}
+
+dispatch:
+	for items.Next() {
+		job := &transformJob{index: len(all), data: items.Value()}
+		all = append(all, job)
+
+		select {
+		case jobs <- job:
+		case <-ctx.Done():
+			break dispatch
+		}
+	}
+
+	close(jobs)
+	wg.Wait()
+
+	if panicked {
+		panic(firstPanic)
+	}
+
+	if firstErr != nil {
+		return nil, nil, firstErr
+	}
+
+	// The context of the scope is done
+	if err := ctx.Err(); err != nil {
+		return nil, nil, err
+	}
+
+	if err := items.Err(); err != nil {
+		return nil, nil, err
+	}
+
+	transformedData := make([]Any, len(all))
+	includedData := make([]M, len(all))
+
+	for i, job := range all {
+		transformedData[i], includedData[i] = job.transformed, job.included
+	}
+
+	return transformedData, includedData, nil
+}
+
 func (s *Scope) serializeResource(serializer Serializer, data Any) M {
 	resourceKey := s.resource.GetResourceKey()
 
@@ -153,20 +682,97 @@ func (s *Scope) serializeResource(serializer Serializer, data Any) M {
 	return serializer.Null()
 }
 
-func (s *Scope) fireTransformer(transformer Transformer, data Any) (M, M) {
+func (s *Scope) fireTransformer(ctx *TransformContext, transformer Transformer, data Any) (M, M, error) {
 	var includedData M
 
-	transformer.SetCurrentScope(s)
-	transformedData := transformer.Transform(data)
+	transformedData, err := s.transform(ctx, transformer, data)
+	if err != nil {
+		return nil, nil, err
+	}
 
 	if s.transformerHasIncludes(transformer) {
-		includedData = s.fireIncludedTransformers(transformer, data)
-		transformedData = s.manager.GetSerializer().MergeIncludes(transformedData, includedData)
+		if includedData, err = s.fireIncludedTransformers(ctx, transformer, data); err != nil {
+			return nil, nil, err
+		}
+
+		// Side-loaded includes are serialized along with the whole resource instead,
+		// which also saves serializers embedding ArraySerializer from merging them.
+		if serializer := s.manager.GetSerializer(); !serializer.SideloadIncludes() {
+			transformedData = serializer.MergeIncludes(transformedData, includedData)
+		}
 	}
 
 	// Stick only with requested fields
 	transformedData = s.filterFieldsets(transformedData)
-	return transformedData, includedData
+
+	// The links of the item are rendered by the serializer
+	if linker, ok := transformer.(Linker); ok {
+		if serializer, ok := s.manager.GetSerializer().(LinkSerializer); ok {
+			transformedData = serializer.InjectLinks(transformedData, linker.Links(data))
+		}
+	}
+
+	return transformedData, includedData, nil
+}
+
+// Transform the data, prefer TransformWithContext and then TransformWithError if the
+// transformer implements them. The current scope is only set on transformers which
+// are not ContextTransformers, for those still calling GetCurrentScope.
+func (s *Scope) transform(ctx *TransformContext, transformer Transformer, data Any) (M, error) {
+	var transformed M
+	var err error
+
+	switch t := transformer.(type) {
+	case ContextTransformer:
+		transformed, err = t.TransformWithContext(ctx, data)
+	case ErrorTransformer:
+		transformer.SetCurrentScope(s)
+		transformed, err = t.TransformWithError(data)
+	default:
+		transformer.SetCurrentScope(s)
+		transformed = transformer.Transform(data)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	if transformed == nil {
+		transformed = M{}
+	}
+
+	return s.authorizeFields(transformed, data)
+}
+
+// Drop the fields the principal is not allowed to see, or fail if any of them is
+// rejected. The transformed data is copied before any field is dropped.
+func (s *Scope) authorizeFields(transformed M, data Any) (M, error) {
+	authorizer := s.manager.fieldAuthorizer
+	if authorizer == nil {
+		return transformed, nil
+	}
+
+	authorized := transformed
+	copied := false
+
+	for _, field := range sortedKeys(transformed) {
+		path := s.getIncludePath(field)
+
+		switch authorizer(s, path, data) {
+		case AuthorizationDrop:
+			if !copied {
+				authorized, copied = M{}, true
+				for k, v := range transformed {
+					authorized[k] = v
+				}
+			}
+			delete(authorized, field)
+		case AuthorizationReject:
+			return nil, &ForbiddenError{Path: path}
+		}
+	}
+
+	return authorized, nil
 }
 
 func (s *Scope) transformerHasIncludes(transformer Transformer) bool {
@@ -177,14 +783,165 @@ func (s *Scope) transformerHasIncludes(transformer Transformer) bool {
 	return len(defaultIncludes) != 0 || len(availableIncludes) != 0
 }
 
-func (s *Scope) fireIncludedTransformers(transformer Transformer, data Any) M {
-	s.availableIncludes = transformer.GetAvailableIncludes()
-	return transformer.ProcessIncludedResources(s, data)
+func (s *Scope) fireIncludedTransformers(ctx *TransformContext, transformer Transformer, data Any) (M, error) {
+	return transformer.ProcessIncludedResources(ctx, data)
+}
+
+// Remember the available includes of the transformer, which is done once per
+// scope instead of once per item, so items can be transformed concurrently.
+func (s *Scope) setAvailableIncludes(transformer Transformer) {
+	s.offerIncludes(transformer)
+
+	if transformer != nil && s.transformerHasIncludes(transformer) {
+		s.availableIncludes = transformer.GetAvailableIncludes()
+		s.defaultIncludes = transformer.GetDefaultIncludes()
+		if mapper, ok := transformer.(includeNameMapper); ok {
+			s.availableIncludes = mapper.publicIncludes(s.availableIncludes)
+			s.defaultIncludes = mapper.publicIncludes(s.defaultIncludes)
+		}
+	}
+}
+
+// GetAvailableIncludes get the public names of the available includes of the scope,
+// which are known once the scope starts to be transformed.
+func (s *Scope) GetAvailableIncludes() []string {
+	return s.availableIncludes
+}
+
+// GetDefaultIncludes get the public names of the default includes of the scope,
+// which are known once the scope starts to be transformed.
+func (s *Scope) GetDefaultIncludes() []string {
+	return s.defaultIncludes
+}
+
+// Get the includes the principal of the scope is allowed to see
+func (s *Scope) authorizedIncludes(includes []string) []string {
+	authorizer := s.manager.includeAuthorizer
+	authorized := []string{}
+
+	for _, include := range includes {
+		if authorizer == nil || authorizer(s, s.getIncludePath(include)) == AuthorizationAllow {
+			authorized = append(authorized, include)
+		}
+	}
+
+	return authorized
+}
+
+// Record the includes the transformer offers at the scope, which are validated in strict mode
+func (s *Scope) offerIncludes(transformer Transformer) {
+	if s.render == nil || !s.manager.IsStrictIncludes() {
+		return
+	}
+
+	var includes []string
+
+	if mapper, ok := transformer.(includeNameMapper); ok {
+		includes = mapper.requestableIncludes()
+	} else if transformer != nil {
+		includes = append(append(includes, transformer.GetAvailableIncludes()...), transformer.GetDefaultIncludes()...)
+	}
+
+	s.render.offerIncludes(s.getScopePath(), includes)
+}
+
+// Report the include requested by a deprecated alias to the manager, once per render
+func (s *Scope) deprecateInclude(include, replacement string) {
+	handler := s.manager.deprecatedIncludeHandler
+	if handler == nil {
+		return
+	}
+
+	path := s.getIncludePath(include)
+	if s.render == nil || s.render.markDeprecated(path) {
+		handler(s, path, replacement)
+	}
 }
 
-// EmbedChildScope embed a scope as a child of the current scope
+// EmbedChildScope embed a scope as a child of the current scope,
+// the child scope is transformed in the context of the current scope.
 func (s *Scope) EmbedChildScope(identifier string, resource Resource) *Scope {
-	return s.manager.CreateData(resource, s, WithIdentifier(identifier))
+	child := s.manager.CreateData(resource, s, WithIdentifier(identifier), WithContext(s.ctx), WithPrincipal(s.principal))
+	child.render = s.render
+	return child
+}
+
+// Convert the data of the child scope to the included data of its parent, the
+// included data of the same entity is reused within a render if it is enabled.
+func (s *Scope) toIncludedData() (Any, error) {
+	key, cacheable := includeCacheKeyOf(s)
+	cacheable = cacheable && s.render != nil && s.manager.IsIncludeCacheEnabled()
+
+	if cacheable {
+		if data, ok := s.render.load(key); ok {
+			return data, nil
+		}
+	}
+
+	var included Any
+
+	switch s.resource.(type) {
+	case *Primitive, *PrimitiveCollection:
+		data, err := s.TransformPrimitiveResource()
+		if err != nil {
+			return nil, err
+		}
+		included = data
+	default:
+		data, err := s.ToMap()
+		if err != nil {
+			return nil, err
+		}
+
+		included = data
+		if serializer, ok := s.manager.GetSerializer().(EmbedSerializer); ok {
+			included = serializer.EmbedData(s.resource, data)
+		}
+	}
+
+	if cacheable {
+		s.render.store(key, included)
+	}
+
+	return included, nil
+}
+
+// Start a render if the scope is not rendered as a part of another render yet, the
+// returned function should be called with the error of the render once it is done.
+// In strict mode, the requested includes are validated once the whole render is done.
+func (s *Scope) beginRender() func(err error) error {
+	if s.render != nil {
+		return func(err error) error {
+			return err
+		}
+	}
+
+	s.render = newRenderState()
+	return func(err error) error {
+		render := s.render
+		s.render = nil
+
+		if err == nil && s.manager.IsStrictIncludes() {
+			if unknown := render.unknownIncludes(s.manager.GetRequestedIncludes()); unknown != nil {
+				return unknown
+			}
+		}
+
+		return err
+	}
+}
+
+// GetPrincipal get the principal the scope is transformed for
+func (s *Scope) GetPrincipal() Any {
+	return s.principal
+}
+
+// Context get the context the scope is transformed in, context.Background() if none is set
+func (s *Scope) Context() context.Context {
+	if s.ctx == nil {
+		return context.Background()
+	}
+	return s.ctx
 }
 
 // Filter the provided data with the requested filter fieldset for
@@ -194,10 +951,35 @@ func (s *Scope) filterFieldsets(data M) M {
 		return data
 	}
 
-	// serializer := s.manager.GetSerializer()
-	// requestedFieldset := s.getFilterFieldset()
+	filtered := M{}
+
+	for k, v := range data {
+		if s.isFieldRequested(k) {
+			filtered[k] = v
+		}
+	}
 
-	return data
+	return filtered
+}
+
+// Check if the field is in the requested fieldset for the scope resource,
+// the mandatory fields of the serializer are always requested.
+func (s *Scope) isFieldRequested(field string) bool {
+	if !s.hasFilterFieldset() {
+		return true
+	}
+
+	if containsString(s.getFilterFieldset(), field) {
+		return true
+	}
+
+	serializer, ok := s.manager.GetSerializer().(FieldsetSerializer)
+	return ok && containsString(serializer.MandatoryFields(), field)
+}
+
+// Check if the scope is the root scope which has no parent scopes
+func (s *Scope) isRootScope() bool {
+	return len(s.parentScopes) == 0
 }
 
 // GetScopeIdentifier get the current identifier
@@ -205,14 +987,18 @@ func (s *Scope) GetScopeIdentifier() string {
 	return s.identifier
 }
 
-// GetIdentifier get the unique identifier for this scope
+// GetIdentifier get the unique identifier for this scope, empty identifiers
+// like the one of a root scope without identifier are skipped.
 func (s *Scope) GetIdentifier(appendIdentifier string) string {
 
-	identifierParts := s.parentScopes
-	identifierParts = append(identifierParts, s.identifier)
+	parts := append([]string{}, s.parentScopes...)
+	parts = append(parts, s.identifier, appendIdentifier)
 
-	if appendIdentifier != "" {
-		identifierParts = append(identifierParts, appendIdentifier)
+	identifierParts := []string{}
+	for _, part := range parts {
+		if part != "" {
+			identifierParts = append(identifierParts, part)
+		}
 	}
 
 	return strings.Join(identifierParts, ".")
@@ -240,7 +1026,7 @@ func (s *Scope) getFilterFieldset() []string {
 }
 
 func (s *Scope) hasFilterFieldset() bool {
-	return len(s.getFilterFieldset()) != 0
+	return s.manager.HasFieldset(s.getResourceType())
 }
 
 func (s *Scope) getResourceType() string {
@@ -255,19 +1041,14 @@ func (s *Scope) GetResource() Resource {
 // IsRequested  Check if - in relation to the current scope - this specific segment is allowed.
 // That means, if a.b.c is requested and the current scope is a.b, then c is allowed. If the current
 // scope is a then c is not allowed, even if it is there and potentially transformable.
+// Every segment of the current scope is allowed if a.b.* is requested.
 func (s *Scope) IsRequested(checkScopeSegment string) bool {
 
-	scopeArray := []string{checkScopeSegment}
-
-	if len(s.parentScopes) > 0 {
-		scopeArray = s.parentScopes[1:]
-		scopeArray = append(scopeArray, s.identifier, checkScopeSegment)
-	}
-
-	scopeString := strings.Join(scopeArray, ".")
+	scopeString := s.getIncludePath(checkScopeSegment)
+	wildcard := s.getIncludePath(WildcardInclude)
 
-	for _, exclude := range s.manager.GetRequestedIncludes() {
-		if exclude == scopeString {
+	for _, include := range s.manager.GetRequestedIncludes() {
+		if include == scopeString || include == wildcard {
 			return true
 		}
 	}
@@ -281,14 +1062,7 @@ func (s *Scope) IsRequested(checkScopeSegment string) bool {
 // the list of default or available, requested includes.
 func (s *Scope) IsExcluded(checkScopeSegment string) bool {
 
-	scopeArray := []string{checkScopeSegment}
-
-	if len(s.parentScopes) > 0 {
-		scopeArray = s.parentScopes[1:]
-		scopeArray = append(scopeArray, s.identifier, checkScopeSegment)
-	}
-
-	scopeString := strings.Join(scopeArray, ".")
+	scopeString := s.getIncludePath(checkScopeSegment)
 
 	for _, exclude := range s.manager.GetRequestedExcludes() {
 		if exclude == scopeString {
@@ -299,9 +1073,34 @@ func (s *Scope) IsExcluded(checkScopeSegment string) bool {
 	return false
 }
 
+// Get the path of the scope in relation to the root scope, which is empty for the root scope
+func (s *Scope) getScopePath() string {
+	if s.isRootScope() {
+		return ""
+	}
+
+	return strings.Join(append(append([]string{}, s.parentScopes[1:]...), s.identifier), ".")
+}
+
+// Get the path of the segment in relation to the root scope,
+// which is how includes are requested, e.g. a.b.c
+func (s *Scope) getIncludePath(checkScopeSegment string) string {
+
+	scopeArray := []string{checkScopeSegment}
+
+	if len(s.parentScopes) > 0 {
+		scopeArray = append([]string{}, s.parentScopes[1:]...)
+		scopeArray = append(scopeArray, s.identifier, checkScopeSegment)
+	}
+
+	return strings.Join(scopeArray, ".")
+}
+
 // ScopeOption options for scope object
 type ScopeOption struct {
 	Identifier string
+	Context    context.Context
+	Principal  Any
 }
 
 // ModScopeOption function to modify scope option
@@ -314,6 +1113,21 @@ func WithIdentifier(identifier string) ModScopeOption {
 	}
 }
 
+// WithContext is an easy way to set the context the scope is transformed in
+func WithContext(ctx context.Context) ModScopeOption {
+	return func(option *ScopeOption) {
+		option.Context = ctx
+	}
+}
+
+// WithPrincipal is an easy way to set the principal the scope is transformed for,
+// e.g. the current user, which is passed to the authorizers of the manager.
+func WithPrincipal(principal Any) ModScopeOption {
+	return func(option *ScopeOption) {
+		option.Principal = principal
+	}
+}
+
 // NewScope create new scope
 func NewScope(manager *Manager, resource Resource, opts ...ModScopeOption) *Scope {
 	opt := ScopeOption{}
@@ -326,5 +1140,7 @@ func NewScope(manager *Manager, resource Resource, opts ...ModScopeOption) *Scop
 		manager:    manager,
 		resource:   resource,
 		identifier: opt.Identifier,
+		ctx:        opt.Context,
+		principal:  opt.Principal,
 	}
 }
@@ -16,7 +16,8 @@ func (f *DefaultScopeFactory) CreateChildScopeFor(manager *Manager, parentScope
 	scope := f.CreateScopeFor(manager, resource, opts...)
 
 	// This will be the new children list of parents (parents parents, plus the parent)
-	scopeArray := parentScope.GetParentScopes()
+	// which is copied, as the children of the same parent may be created concurrently.
+	scopeArray := append([]string{}, parentScope.GetParentScopes()...)
 	scopeArray = append(scopeArray, parentScope.GetScopeIdentifier())
 
 	scope.SetParentScopes(scopeArray)
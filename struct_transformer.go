@@ -0,0 +1,449 @@
+package fractal
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// FieldFormatter formats the value of a struct field before it is output
+type FieldFormatter func(value Any) (Any, error)
+
+// StructTransformer transforms structs by the `fractal` tag of their fields,
+// the tag is a name followed by comma separated options, e.g.
+//
+//	type Book struct {
+//		ID        int       `fractal:"id"`
+//		Title     string    `fractal:"title,omitempty"`
+//		Price     float64   `fractal:"price,precision=2"`
+//		CreatedAt time.Time `fractal:"created_at,layout=DateOnly"`
+//		ISBN      string    `fractal:"isbn,format=isbn"`
+//		Author    *User     `fractal:",include=author,key=users"`
+//		Internal  string    `fractal:"-"`
+//	}
+//
+// Fields without the name use the name of the json tag, or the field name.
+// Fields of embedded structs are output as if they were fields of the outer struct.
+//
+// The options are:
+//
+//	omitempty     omit the field if it is the zero value
+//	precision=N   output a float with N decimals
+//	layout=L      output a time.Time with the layout L, or the name of a layout of the time package
+//	format=NAME   output the value formatted by the formatter registered with SetFormatter
+//	include=NAME  declare the field as the available include NAME instead of outputting it
+//	default       make the include a default include
+//	key=KEY       the resource key of the included resource
+//
+// Included structs are transformed by a StructTransformer of their type
+// unless a transformer is set with SetIncludeTransformer.
+type StructTransformer struct {
+	BaseTransformer
+	typ                 reflect.Type
+	info                *structInfo
+	formatters          map[string]FieldFormatter
+	includeTransformers map[string]Transformer
+	customIncluder      ContextIncluder
+	mu                  sync.Mutex
+}
+
+// TransformWithError perform transform, fails if the data is not of the struct type
+func (t *StructTransformer) TransformWithError(data Any) (M, error) {
+	v, err := t.structValue(data)
+	if err != nil || !v.IsValid() {
+		return M{}, err
+	}
+
+	result := M{}
+
+	for _, field := range t.info.fields {
+		fv, ok := fieldByIndex(v, field.index)
+		if !ok || (field.omitEmpty && fv.IsZero()) {
+			continue
+		}
+
+		value, err := t.formatField(field, fv)
+		if err != nil {
+			return nil, fmt.Errorf("can not format field %s: %w", field.name, err)
+		}
+
+		result[field.name] = value
+	}
+
+	return result, nil
+}
+
+// TransformWithContext perform transform, the struct transformer never needs the scope
+func (t *StructTransformer) TransformWithContext(ctx *TransformContext, data Any) (M, error) {
+	return t.TransformWithError(data)
+}
+
+// Transform perform transform
+func (t *StructTransformer) Transform(data Any) M {
+	m, _ := t.TransformWithError(data)
+	return m
+}
+
+// IncludeWithContext include the tagged field, the includer set with SetIncluder
+// is consulted first and the field is only included if it returns no resource.
+func (t *StructTransformer) IncludeWithContext(ctx *TransformContext, includeName string, data Any, params P) (Resource, error) {
+	if t.customIncluder != nil {
+		resource, err := t.customIncluder.IncludeWithContext(ctx, includeName, data, params)
+		if resource != nil || err != nil {
+			return resource, err
+		}
+	}
+
+	include, ok := t.info.includes[includeName]
+	if !ok {
+		return nil, nil
+	}
+
+	v, err := t.structValue(data)
+	if err != nil || !v.IsValid() {
+		return nil, err
+	}
+
+	fv, ok := fieldByIndex(v, include.index)
+	if !ok {
+		return nil, nil
+	}
+
+	switch fv.Kind() {
+	case reflect.Ptr, reflect.Interface, reflect.Slice, reflect.Map:
+		if fv.IsNil() {
+			return nil, nil
+		}
+	}
+
+	transformer, err := t.getIncludeTransformer(include)
+	if err != nil {
+		return nil, err
+	}
+
+	opts := []ModResourceOption{
+		WithData(fv.Interface()),
+		WithResourceKey(include.resourceKey),
+		WithTransformer(transformer),
+	}
+
+	if include.collection {
+		return t.Collection(opts...), nil
+	}
+
+	return t.Item(opts...), nil
+}
+
+// SetIncluder setter for the hand-written includer, which is consulted before the tagged fields,
+// IncludeWithContext or IncludeWithError is called instead of Include if it implements them.
+// If it is a BatchIncluder, it should return nil for the tagged fields it does not include.
+func (t *StructTransformer) SetIncluder(includer Includer) *StructTransformer {
+	t.customIncluder = toContextIncluder(includer)
+	t.batchIncluder, _ = includer.(BatchIncluder)
+	return t
+}
+
+// SetFormatter register a formatter used by the fields tagged with format=name
+func (t *StructTransformer) SetFormatter(name string, formatter FieldFormatter) *StructTransformer {
+	t.formatters[name] = formatter
+	return t
+}
+
+// SetIncludeTransformer set the transformer of the included field
+func (t *StructTransformer) SetIncludeTransformer(includeName string, transformer Transformer) *StructTransformer {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.includeTransformers[includeName] = transformer
+	return t
+}
+
+// Get the transformer of the included field, a StructTransformer
+// of the included type is created on first use if none is set.
+func (t *StructTransformer) getIncludeTransformer(include *structInclude) (Transformer, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if transformer, ok := t.includeTransformers[include.name]; ok {
+		return transformer, nil
+	}
+
+	if indirectType(include.elemType).Kind() != reflect.Struct {
+		return nil, fmt.Errorf("no transformer is set for include %s of type %s", include.name, include.elemType)
+	}
+
+	transformer := NewStructTransformer(reflect.Zero(include.elemType).Interface())
+	t.includeTransformers[include.name] = transformer
+	return transformer, nil
+}
+
+// Get the struct value of the data, the value is invalid if the data is a nil pointer
+func (t *StructTransformer) structValue(data Any) (reflect.Value, error) {
+	v := reflect.ValueOf(data)
+
+	for v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return reflect.Value{}, nil
+		}
+		v = v.Elem()
+	}
+
+	if !v.IsValid() {
+		return v, nil
+	}
+
+	if v.Type() != t.typ {
+		return v, fmt.Errorf("the transformer expects data of type %s but got %T", t.typ, data)
+	}
+
+	return v, nil
+}
+
+func (t *StructTransformer) formatField(field *structField, v reflect.Value) (Any, error) {
+	if field.format != "" {
+		formatter, ok := t.formatters[field.format]
+		if !ok {
+			return nil, fmt.Errorf("formatter %s is not registered", field.format)
+		}
+		return formatter(v.Interface())
+	}
+
+	if field.layout == "" && field.precision < 0 {
+		return v.Interface(), nil
+	}
+
+	for v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return nil, nil
+		}
+		v = v.Elem()
+	}
+
+	if field.layout != "" {
+		return v.Interface().(time.Time).Format(field.layout), nil
+	}
+
+	return json.Number(strconv.FormatFloat(v.Float(), 'f', field.precision, 64)), nil
+}
+
+// structInfo the parsed fields of a struct type
+type structInfo struct {
+	fields          []*structField
+	includes        map[string]*structInclude
+	includeNames    []string
+	defaultIncludes []string
+}
+
+type structField struct {
+	name      string
+	index     []int
+	omitEmpty bool
+	precision int
+	layout    string
+	format    string
+}
+
+type structInclude struct {
+	name        string
+	index       []int
+	resourceKey string
+	elemType    reflect.Type
+	collection  bool
+}
+
+var structInfoCache sync.Map
+
+var timeType = reflect.TypeOf(time.Time{})
+
+var timeLayouts = map[string]string{
+	"ANSIC":       time.ANSIC,
+	"UnixDate":    time.UnixDate,
+	"RubyDate":    time.RubyDate,
+	"RFC822":      time.RFC822,
+	"RFC822Z":     time.RFC822Z,
+	"RFC850":      time.RFC850,
+	"RFC1123":     time.RFC1123,
+	"RFC1123Z":    time.RFC1123Z,
+	"RFC3339":     time.RFC3339,
+	"RFC3339Nano": time.RFC3339Nano,
+	"Kitchen":     time.Kitchen,
+	"DateTime":    "2006-01-02 15:04:05",
+	"DateOnly":    "2006-01-02",
+	"TimeOnly":    "15:04:05",
+}
+
+// Get the parsed fields of the struct type, parsed once per type
+func getStructInfo(typ reflect.Type) (*structInfo, error) {
+	if info, ok := structInfoCache.Load(typ); ok {
+		return info.(*structInfo), nil
+	}
+
+	info := &structInfo{includes: map[string]*structInclude{}}
+	if err := info.parse(typ, nil); err != nil {
+		return nil, err
+	}
+
+	structInfoCache.Store(typ, info)
+	return info, nil
+}
+
+func (info *structInfo) parse(typ reflect.Type, index []int) error {
+	for i := 0; i < typ.NumField(); i++ {
+		f := typ.Field(i)
+		tag := f.Tag.Get("fractal")
+		if tag == "-" {
+			continue
+		}
+
+		fieldIndex := append(append([]int{}, index...), i)
+		parts := strings.Split(tag, ",")
+		name := parts[0]
+
+		// Fields of embedded structs are promoted to the outer struct
+		if f.Anonymous && name == "" && indirectType(f.Type).Kind() == reflect.Struct {
+			if err := info.parse(indirectType(f.Type), fieldIndex); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if f.PkgPath != "" {
+			continue
+		}
+
+		if name == "" {
+			name = strings.Split(f.Tag.Get("json"), ",")[0]
+		}
+		if name == "" || name == "-" {
+			name = f.Name
+		}
+
+		field := &structField{name: name, index: fieldIndex, precision: -1}
+		var include *structInclude
+		isDefault := false
+
+		for _, option := range parts[1:] {
+			key, value := option, ""
+			if i := strings.Index(option, "="); i >= 0 {
+				key, value = option[:i], option[i+1:]
+			}
+
+			switch key {
+			case "omitempty":
+				field.omitEmpty = true
+			case "default":
+				isDefault = true
+			case "include":
+				include = &structInclude{name: value, index: fieldIndex, elemType: f.Type}
+			case "key":
+				if include == nil {
+					return fmt.Errorf("field %s: key should follow the include option", f.Name)
+				}
+				include.resourceKey = value
+			case "format":
+				field.format = value
+			case "layout":
+				if indirectType(f.Type) != timeType {
+					return fmt.Errorf("field %s: layout requires a time.Time field", f.Name)
+				}
+				if layout, ok := timeLayouts[value]; ok {
+					value = layout
+				}
+				field.layout = value
+			case "precision":
+				precision, err := strconv.Atoi(value)
+				if err != nil || precision < 0 {
+					return fmt.Errorf("field %s: invalid precision %q", f.Name, value)
+				}
+				switch indirectType(f.Type).Kind() {
+				case reflect.Float32, reflect.Float64:
+				default:
+					return fmt.Errorf("field %s: precision requires a float field", f.Name)
+				}
+				field.precision = precision
+			default:
+				return fmt.Errorf("field %s: unknown option %q", f.Name, option)
+			}
+		}
+
+		if include == nil {
+			if isDefault {
+				return fmt.Errorf("field %s: default requires the include option", f.Name)
+			}
+			info.fields = append(info.fields, field)
+			continue
+		}
+
+		if include.name == "" {
+			return fmt.Errorf("field %s: include name should not be empty", f.Name)
+		}
+
+		switch f.Type.Kind() {
+		case reflect.Slice, reflect.Array:
+			include.collection = true
+			include.elemType = f.Type.Elem()
+		}
+
+		info.includes[include.name] = include
+		info.includeNames = append(info.includeNames, include.name)
+		if isDefault {
+			info.defaultIncludes = append(info.defaultIncludes, include.name)
+		}
+	}
+
+	return nil
+}
+
+// Get the field by its index, ok is false if an embedded struct pointer on the way is nil
+func fieldByIndex(v reflect.Value, index []int) (reflect.Value, bool) {
+	for i, x := range index {
+		if i > 0 {
+			for v.Kind() == reflect.Ptr {
+				if v.IsNil() {
+					return reflect.Value{}, false
+				}
+				v = v.Elem()
+			}
+		}
+		v = v.Field(x)
+	}
+	return v, true
+}
+
+func indirectType(typ reflect.Type) reflect.Type {
+	for typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	return typ
+}
+
+// NewStructTransformer create new struct transformer for the type of the model,
+// which is a struct or a pointer to struct. The available and default includes
+// are declared by the tagged fields. It panics if the tags are invalid.
+func NewStructTransformer(model Any) *StructTransformer {
+	typ := indirectType(reflect.TypeOf(model))
+	if typ.Kind() != reflect.Struct {
+		panic(fmt.Sprintf("fractal: struct transformer expects a struct model but got %s", typ))
+	}
+
+	info, err := getStructInfo(typ)
+	if err != nil {
+		panic(fmt.Sprintf("fractal: invalid fractal tag of %s: %s", typ, err))
+	}
+
+	t := &StructTransformer{
+		typ:                 typ,
+		info:                info,
+		formatters:          map[string]FieldFormatter{},
+		includeTransformers: map[string]Transformer{},
+	}
+
+	t.BaseTransformer.SetContextIncluder(t)
+	t.SetAvailableIncludes(append([]string{}, info.includeNames...))
+	t.SetDefaultIncludes(append([]string{}, info.defaultIncludes...))
+	return t
+}
@@ -0,0 +1,137 @@
+package fractal_test
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ibllex/go-fractal"
+	"github.com/stretchr/testify/assert"
+)
+
+type Timestamps struct {
+	CreatedAt time.Time  `fractal:"created_at,layout=DateOnly"`
+	DeletedAt *time.Time `fractal:"deleted_at,omitempty,layout=RFC3339"`
+}
+
+type Author struct {
+	ID   int    `json:"id"`
+	Name string `fractal:"name"`
+}
+
+type Article struct {
+	Timestamps
+	ID       int       `fractal:"id"`
+	Title    string    `fractal:"title,format=upper"`
+	Price    float64   `fractal:"price,precision=2"`
+	Summary  string    `fractal:"summary,omitempty"`
+	Secret   string    `fractal:"-"`
+	Author   *Author   `fractal:",include=author,default,key=authors"`
+	Comments []*Author `fractal:",include=comments"`
+}
+
+type AnonymousAuthorIncluder struct {
+	fractal.BaseTransformer
+}
+
+func (i *AnonymousAuthorIncluder) Include(includeName string, data fractal.Any, params fractal.P) fractal.Resource {
+	if includeName == "author" {
+		return i.Item(
+			fractal.WithData(&Author{Name: "anonymous"}),
+			fractal.WithTransformer(fractal.NewStructTransformer(Author{})),
+		)
+	}
+	return nil
+}
+
+func TestStructTransformer(t *testing.T) {
+	article := &Article{
+		Timestamps: Timestamps{CreatedAt: time.Date(2021, 6, 1, 8, 0, 0, 0, time.UTC)},
+		ID:         1,
+		Title:      "hogfather",
+		Price:      9.5,
+		Secret:     "secret",
+		Author:     &Author{ID: 1, Name: "Terry"},
+		Comments:   []*Author{{ID: 2, Name: "Sam"}},
+	}
+
+	newTransformer := func() *fractal.StructTransformer {
+		return fractal.NewStructTransformer(Article{}).SetFormatter("upper", func(value fractal.Any) (fractal.Any, error) {
+			return strings.ToUpper(value.(string)), nil
+		})
+	}
+
+	t.Run("fields and default includes", func(t *testing.T) {
+		manager := fractal.NewManager(nil)
+		resource := fractal.NewItem(fractal.WithData(article), fractal.WithTransformer(newTransformer()))
+
+		expected := fractal.M{"data": fractal.M{
+			"created_at": "2021-06-01",
+			"id":         1,
+			"title":      "HOGFATHER",
+			"price":      json.Number("9.50"),
+			"author":     fractal.M{"data": fractal.M{"id": 1, "name": "Terry"}},
+		}}
+
+		actual, err := manager.CreateData(resource, nil).ToMap()
+
+		assert.Nil(t, err)
+		assert.Equal(t, expected, actual)
+	})
+
+	t.Run("collection include", func(t *testing.T) {
+		manager := fractal.NewManager(nil)
+		manager.ParseIncludes([]string{"comments"})
+		manager.ParseExcludes([]string{"author"})
+		resource := fractal.NewItem(fractal.WithData(article), fractal.WithTransformer(newTransformer()))
+
+		actual, err := manager.CreateData(resource, nil).ToMap()
+
+		assert.Nil(t, err)
+		assert.Equal(t, fractal.M{"data": []fractal.Any{
+			fractal.M{"id": 2, "name": "Sam"},
+		}}, actual["data"].(fractal.M)["comments"])
+		assert.NotContains(t, actual["data"], "author")
+	})
+
+	t.Run("hand-written includer", func(t *testing.T) {
+		manager := fractal.NewManager(nil)
+		transformer := newTransformer()
+		transformer.SetIncluder(&AnonymousAuthorIncluder{})
+
+		resource := fractal.NewItem(fractal.WithData(article), fractal.WithTransformer(transformer))
+
+		actual, err := manager.CreateData(resource, nil).ToMap()
+
+		assert.Nil(t, err)
+		assert.Equal(t, fractal.M{"data": fractal.M{"id": 0, "name": "anonymous"}}, actual["data"].(fractal.M)["author"])
+	})
+
+	t.Run("available includes", func(t *testing.T) {
+		transformer := newTransformer()
+
+		assert.Equal(t, []string{"author", "comments"}, transformer.GetAvailableIncludes())
+		assert.Equal(t, []string{"author"}, transformer.GetDefaultIncludes())
+	})
+
+	t.Run("mismatched data", func(t *testing.T) {
+		manager := fractal.NewManager(nil)
+		resource := fractal.NewItem(fractal.WithData(&Book{}), fractal.WithTransformer(newTransformer()))
+
+		_, err := manager.CreateData(resource, nil).ToMap()
+
+		assert.EqualError(t, err, "the transformer expects data of type fractal_test.Article but got *fractal_test.Book")
+	})
+
+	t.Run("invalid tag", func(t *testing.T) {
+		type Invalid struct {
+			Name string `fractal:"name,precision=2"`
+		}
+
+		assert.PanicsWithValue(t,
+			"fractal: invalid fractal tag of fractal_test.Invalid: field Name: precision requires a float field",
+			func() { fractal.NewStructTransformer(Invalid{}) },
+		)
+	})
+}
@@ -0,0 +1,32 @@
+package fractal
+
+import "context"
+
+// TransformContext holds the state of a single transformation, it is passed along
+// with the data instead of being stored on the transformer, so a transformer can
+// be shared by concurrent transformations.
+//
+// It is the context.Context of the scope as well, which can be passed to
+// database queries and the like, e.g. db.QueryContext(ctx, ...)
+type TransformContext struct {
+	context.Context
+	scope *Scope
+	// The resources included by batch and the index of the item among them
+	batch includeBatch
+	index int
+}
+
+// GetScope get the scope the data is transformed in
+func (c *TransformContext) GetScope() *Scope {
+	return c.scope
+}
+
+// GetIncludeParams get the params of the include of the current scope
+func (c *TransformContext) GetIncludeParams(includeName string) P {
+	return c.scope.GetManager().GetIncludeParams(c.scope.getIncludePath(includeName))
+}
+
+// Create the context of the transformation of an item of the scope
+func newTransformContext(scope *Scope) *TransformContext {
+	return &TransformContext{Context: scope.Context(), scope: scope}
+}
@@ -0,0 +1,210 @@
+package fractal_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/ibllex/go-fractal"
+	"github.com/stretchr/testify/assert"
+)
+
+// SharedCategoryTransformer never stores the scope, so a single instance can be
+// used by concurrent transformations.
+type SharedCategoryTransformer struct {
+	fractal.BaseTransformer
+}
+
+func (t *SharedCategoryTransformer) TransformWithContext(ctx *fractal.TransformContext, data fractal.Any) (fractal.M, error) {
+	c := data.(*Category)
+	return fractal.M{"id": c.ID, "identifier": ctx.GetScope().GetIdentifier("")}, nil
+}
+
+func (t *SharedCategoryTransformer) IncludeWithContext(ctx *fractal.TransformContext, includeName string, data fractal.Any, params fractal.P) (fractal.Resource, error) {
+	books := []fractal.Any{}
+	limit, _ := strconv.Atoi(params.First("limit"))
+
+	for _, b := range data.(*Category).Books[:limit] {
+		books = append(books, b)
+	}
+
+	return t.Collection(
+		fractal.WithData(books),
+		fractal.WithTransformer(fractal.T(func(t *fractal.BaseTransformer, data fractal.Any) fractal.M {
+			return fractal.M{"id": data.(*Book).ID}
+		})),
+	), nil
+}
+
+func NewSharedCategoryTransformer() *SharedCategoryTransformer {
+	t := &SharedCategoryTransformer{}
+	t.SetContextIncluder(t).SetAvailableIncludes([]string{"books"})
+	return t
+}
+
+func TestTransformContext(t *testing.T) {
+	cat := &Category{ID: 1}
+	for i := 1; i <= 10; i++ {
+		cat.Books = append(cat.Books, &Book{ID: i})
+	}
+
+	transformer := NewSharedCategoryTransformer()
+
+	t.Run("concurrent reuse", func(t *testing.T) {
+		var wg sync.WaitGroup
+
+		for i := 1; i <= 10; i++ {
+			wg.Add(1)
+			go func(limit int) {
+				defer wg.Done()
+
+				manager := fractal.NewManager(nil).SetConcurrency(2)
+				manager.ParseIncludes([]string{fmt.Sprintf("books:limit(%d)", limit)})
+
+				identifier := fmt.Sprintf("categories%d", limit)
+				resource := fractal.NewCollection(
+					fractal.WithData([]*Category{cat, cat}),
+					fractal.WithTransformer(transformer),
+				)
+
+				actual, err := manager.CreateData(resource, nil, fractal.WithIdentifier(identifier)).ToMap()
+				assert.Nil(t, err)
+
+				for _, item := range actual["data"].([]fractal.Any) {
+					assert.Equal(t, identifier, item.(fractal.M)["identifier"])
+					assert.Len(t, item.(fractal.M)["books"].(fractal.M)["data"], limit)
+				}
+			}(i)
+		}
+
+		wg.Wait()
+	})
+
+	t.Run("shared closures", func(t *testing.T) {
+		// Both closures read the current scope of the transformer they are given
+		books := fractal.T(func(t *fractal.BaseTransformer, data fractal.Any) fractal.M {
+			return fractal.M{"id": data.(*Book).ID, "identifier": t.GetCurrentScope().GetIdentifier("")}
+		})
+
+		categories := fractal.TOf(func(t *fractal.BaseTransformer, c *Category) fractal.M {
+			return fractal.M{"id": c.ID, "identifier": t.GetCurrentScope().GetIdentifier("")}
+		})
+		categories.SetAvailableIncludes([]string{"books"})
+		categories.SetContextIncluder(contextIncluderFunc(func(ctx *fractal.TransformContext) (fractal.Resource, error) {
+			return categories.Collection(fractal.WithData(cat.Books), fractal.WithTransformer(books)), nil
+		}))
+
+		var wg sync.WaitGroup
+
+		for i := 1; i <= 10; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+
+				manager := fractal.NewManager(nil).SetConcurrency(2)
+				manager.ParseIncludes([]string{"books"})
+
+				identifier := fmt.Sprintf("categories%d", i)
+				resource := fractal.NewCollectionOf([]*Category{cat, cat, cat}, categories)
+
+				actual, err := manager.CreateData(resource, nil, fractal.WithIdentifier(identifier)).ToMap()
+				assert.Nil(t, err)
+
+				for _, item := range actual["data"].([]fractal.Any) {
+					assert.Equal(t, identifier, item.(fractal.M)["identifier"])
+
+					for _, book := range item.(fractal.M)["books"].(fractal.M)["data"].([]fractal.Any) {
+						assert.Equal(t, identifier+".books", book.(fractal.M)["identifier"])
+					}
+				}
+			}(i)
+		}
+
+		wg.Wait()
+	})
+
+	t.Run("current scope of legacy transformers", func(t *testing.T) {
+		var current *fractal.Scope
+
+		legacy := fractal.T(func(t *fractal.BaseTransformer, data fractal.Any) fractal.M {
+			current = t.GetCurrentScope()
+			return fractal.M{}
+		})
+
+		scope := fractal.NewManager(nil).CreateData(fractal.NewItem(fractal.WithTransformer(legacy)), nil)
+		_, err := scope.ToMap()
+
+		assert.Nil(t, err)
+		assert.Same(t, scope, current)
+	})
+}
+
+type ctxKey struct{}
+
+func TestContextPropagation(t *testing.T) {
+	cat := &Category{ID: 1}
+	for i := 1; i <= 10; i++ {
+		cat.Books = append(cat.Books, &Book{ID: i})
+	}
+
+	// The transformer of the books outputs the value of the context
+	valueTransformer := fractal.T(func(t *fractal.BaseTransformer, data fractal.Any) fractal.M {
+		return fractal.M{"value": t.GetCurrentScope().Context().Value(ctxKey{})}
+	})
+
+	newResource := func(includer func(ctx *fractal.TransformContext) error) *fractal.Collection {
+		transformer := &SharedCategoryTransformer{}
+		transformer.SetAvailableIncludes([]string{"books"})
+		transformer.SetContextIncluder(contextIncluderFunc(func(ctx *fractal.TransformContext) (fractal.Resource, error) {
+			if err := includer(ctx); err != nil {
+				return nil, err
+			}
+			return transformer.Item(fractal.WithData(cat.Books[0]), fractal.WithTransformer(valueTransformer)), nil
+		}))
+
+		return fractal.NewCollection(
+			fractal.WithData([]*Category{cat, cat, cat}),
+			fractal.WithTransformer(transformer),
+		)
+	}
+
+	manager := fractal.NewManager(nil)
+	manager.ParseIncludes([]string{"books"})
+
+	t.Run("values", func(t *testing.T) {
+		ctx := context.WithValue(context.Background(), ctxKey{}, "traced")
+
+		actual, err := manager.CreateData(newResource(func(ctx *fractal.TransformContext) error {
+			assert.Equal(t, "traced", ctx.Value(ctxKey{}))
+			return nil
+		}), nil).ToMapContext(ctx)
+
+		assert.Nil(t, err)
+		for _, item := range actual["data"].([]fractal.Any) {
+			assert.Equal(t, fractal.M{"data": fractal.M{"value": "traced"}}, item.(fractal.M)["books"])
+		}
+	})
+
+	t.Run("cancellation", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		included := 0
+
+		_, err := manager.CreateData(newResource(func(ctx *fractal.TransformContext) error {
+			included++
+			cancel()
+			return nil
+		}), nil, fractal.WithContext(ctx)).ToMap()
+
+		assert.True(t, errors.Is(err, context.Canceled))
+		assert.Equal(t, 1, included)
+	})
+}
+
+type contextIncluderFunc func(ctx *fractal.TransformContext) (fractal.Resource, error)
+
+func (f contextIncluderFunc) IncludeWithContext(ctx *fractal.TransformContext, includeName string, data fractal.Any, params fractal.P) (fractal.Resource, error) {
+	return f(ctx)
+}
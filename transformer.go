@@ -1,5 +1,7 @@
 package fractal
 
+import "fmt"
+
 // BaseTransformer All Transformer classes should extend this to utilize the convenience methods
 // collection() and item(), and make the availableIncludes property available.
 // Extend it and add a `Transform()` method to transform any default or included data
@@ -12,7 +14,18 @@ type BaseTransformer struct {
 	// The transformer should know about the current scope, so we can fetch relevant params
 	currentScope *Scope
 	// The transformer should have an includer to perform custom includes
-	includer Includer
+	includer ContextIncluder
+	// The includer if it includes the resources of all the items at once
+	batchIncluder BatchIncluder
+	// The public names the includes are requested by
+	includeAliases []includeAlias
+}
+
+// includeAlias maps a public include name to the internal include name
+type includeAlias struct {
+	public     string
+	internal   string
+	deprecated bool
 }
 
 // Transform perform transform
@@ -56,80 +69,301 @@ func (t *BaseTransformer) SetCurrentScope(scope *Scope) Transformer {
 	return t
 }
 
-// SetIncluder setter for includer
+// Get a copy of the transformer whose current scope is the scope of the transform
+// context, closures get the copy so the transformer itself is never modified.
+func (t *BaseTransformer) withScope(ctx *TransformContext) *BaseTransformer {
+	copied := *t
+	copied.currentScope = ctx.GetScope()
+	return &copied
+}
+
+// SetIncluder setter for includer, IncludeWithContext or IncludeWithError is called
+// instead of Include if the includer is a ContextIncluder or an ErrorIncluder as well.
 func (t *BaseTransformer) SetIncluder(includer Includer) *BaseTransformer {
-	t.includer = includer
+	return t.setIncluder(includer)
+}
+
+// SetErrorIncluder setter for includer whose includes may fail
+func (t *BaseTransformer) SetErrorIncluder(includer ErrorIncluder) *BaseTransformer {
+	return t.setIncluder(includer)
+}
+
+// SetContextIncluder setter for includer which gets the scope from the transform context
+func (t *BaseTransformer) SetContextIncluder(includer ContextIncluder) *BaseTransformer {
+	return t.setIncluder(includer)
+}
+
+// The includes of collections are included by batch if the includer is a BatchIncluder
+func (t *BaseTransformer) setIncluder(includer Any) *BaseTransformer {
+	t.includer = toContextIncluder(includer)
+	t.batchIncluder, _ = includer.(BatchIncluder)
+	return t
+}
+
+// SetIncludeAlias expose the include by the public name, e.g. "author" for "creator".
+// The internal name is the one in the available and default includes, which is passed
+// to the includer, while the public name is the one requested by the clients and used
+// by the output, the include params and the identifier of the child scope.
+// An include with aliases is no longer requested by its internal name.
+func (t *BaseTransformer) SetIncludeAlias(public, internal string) *BaseTransformer {
+	t.includeAliases = append(t.includeAliases, includeAlias{public: public, internal: internal})
 	return t
 }
 
+// SetDeprecatedIncludeAlias accept a deprecated public name of the include, which is still
+// rendered by the name it is requested by, the deprecated include handler of the manager
+// is called when it is requested. Deprecated names are never requested by a wildcard.
+func (t *BaseTransformer) SetDeprecatedIncludeAlias(alias, internal string) *BaseTransformer {
+	t.includeAliases = append(t.includeAliases, includeAlias{public: alias, internal: internal, deprecated: true})
+	return t
+}
+
+// includeNameMapper is implemented by the transformers embedding BaseTransformer
+type includeNameMapper interface {
+	publicIncludes(includes []string) []string
+	requestableIncludes() []string
+}
+
+// Get the public names the internal include is requested by, the deprecated ones are last
+func (t *BaseTransformer) publicNamesOf(include string) []string {
+	names := []string{}
+	deprecated := []string{}
+
+	for _, alias := range t.includeAliases {
+		if alias.internal != include {
+			continue
+		}
+
+		if alias.deprecated {
+			deprecated = append(deprecated, alias.public)
+		} else {
+			names = append(names, alias.public)
+		}
+	}
+
+	if len(names) == 0 {
+		names = append(names, include)
+	}
+
+	return append(names, deprecated...)
+}
+
+// Get the public name an internal include is rendered by if it is not requested by another name
+func (t *BaseTransformer) primaryNameOf(include string) string {
+	return t.publicNamesOf(include)[0]
+}
+
+// Get the public names of the internal includes, without the deprecated ones
+func (t *BaseTransformer) publicIncludes(includes []string) []string {
+	if len(t.includeAliases) == 0 {
+		return includes
+	}
+
+	names := make([]string, len(includes))
+	for i, include := range includes {
+		names[i] = t.primaryNameOf(include)
+	}
+
+	return names
+}
+
+// Get all the public names the available and default includes can be requested by
+func (t *BaseTransformer) requestableIncludes() []string {
+	names := []string{}
+
+	for _, include := range append(append([]string{}, t.GetAvailableIncludes()...), t.GetDefaultIncludes()...) {
+		for _, name := range t.publicNamesOf(include) {
+			if !containsString(names, name) {
+				names = append(names, name)
+			}
+		}
+	}
+
+	return names
+}
+
+// Get the internal name of the public include name
+func (t *BaseTransformer) internalNameOf(include string) string {
+	for _, alias := range t.includeAliases {
+		if alias.public == include {
+			return alias.internal
+		}
+	}
+
+	return include
+}
+
+// Get the include name the deprecated alias should be replaced by, ok is false if the alias is not deprecated
+func (t *BaseTransformer) replacementOf(include string) (replacement string, ok bool) {
+	for _, alias := range t.includeAliases {
+		if alias.public == include && alias.deprecated {
+			return t.primaryNameOf(alias.internal), true
+		}
+	}
+
+	return "", false
+}
+
 // ProcessIncludedResources is fired to loop through available includes,
 // see if any of them are requested and permitted for this scope.
-func (t *BaseTransformer) ProcessIncludedResources(scope *Scope, data Any) M {
+func (t *BaseTransformer) ProcessIncludedResources(ctx *TransformContext, data Any) (M, error) {
 	includedData := M{}
 
-	includes := t.figureOutWhichIncludes(scope)
+	includes, err := t.figureOutWhichIncludes(ctx.GetScope())
+	if err != nil {
+		return nil, err
+	}
 
 	for _, include := range includes {
-		includedData = t.includeResourceIfAvailable(
-			scope, data, includedData, include,
-		)
+		// Stop including once the context of the scope is done
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		if err := t.includeResourceIfAvailable(ctx, data, includedData, include); err != nil {
+			return nil, err
+		}
 	}
 
-	return includedData
+	return includedData, nil
 }
 
 // Include a resource only if it is available on the method
-func (t *BaseTransformer) includeResourceIfAvailable(scope *Scope, data Any, includeData M, include string) M {
+func (t *BaseTransformer) includeResourceIfAvailable(ctx *TransformContext, data Any, includeData M, include string) error {
+	scope := ctx.GetScope()
 
-	resource := t.callIncludeMethod(scope, include, data)
+	resource, err := t.callIncludeMethod(ctx, include, data)
+	if err != nil {
+		return wrapScopeError(scope.GetIdentifier(include), err)
+	}
 
 	if resource != nil {
-		childScope := scope.EmbedChildScope(include, resource)
-
-		if _, ok := childScope.GetResource().(*Primitive); ok {
-			includeData[include], _ = childScope.TransformPrimitiveResource()
-		} else if _, ok := childScope.GetResource().(*PrimitiveCollection); ok {
-			includeData[include], _ = childScope.TransformPrimitiveResource()
-		} else {
-			includeData[include], _ = childScope.ToMap()
-		}
+		includeData[include], err = scope.EmbedChildScope(include, resource).toIncludedData()
 	}
 
-	return includeData
+	return err
 }
 
-// Call Include Method
-func (t *BaseTransformer) callIncludeMethod(scope *Scope, includeName string, data Any) Resource {
+// Call Include Method, the includer is called by the internal name of the include
+func (t *BaseTransformer) callIncludeMethod(ctx *TransformContext, includeName string, data Any) (Resource, error) {
+
+	if resources, ok := ctx.batch[includeName]; ok {
+		return resources[ctx.index], nil
+	}
 
 	if t.includer == nil {
-		return nil
+		return nil, nil
 	}
 
-	scopeIdentifier := scope.GetIdentifier(includeName)
-	params := scope.GetManager().GetIncludeParams(scopeIdentifier)
+	return t.includer.IncludeWithContext(ctx, t.internalNameOf(includeName), data, ctx.GetIncludeParams(includeName))
+}
+
+// includeBatch the resources included by batch for every item by the include name
+type includeBatch map[string][]Resource
 
-	return t.includer.Include(includeName, data, params)
+// batchPreloader is implemented by the transformers embedding BaseTransformer
+type batchPreloader interface {
+	canPreloadIncludes() bool
+	preloadIncludes(ctx *TransformContext, items []Any) (includeBatch, error)
 }
 
-// Figure out which includes we need
-func (t *BaseTransformer) figureOutWhichIncludes(scope *Scope) []string {
-	includes := t.GetDefaultIncludes()
+// Check if the includes can be included by batch
+func (t *BaseTransformer) canPreloadIncludes() bool {
+	return t.batchIncluder != nil
+}
 
-	for _, include := range t.GetAvailableIncludes() {
-		if scope.IsRequested(include) {
-			includes = append(includes, include)
+// Include the resources of all the items at once for each of the includes, the
+// includes the BatchIncluder returns nil for are included item by item instead.
+func (t *BaseTransformer) preloadIncludes(ctx *TransformContext, items []Any) (includeBatch, error) {
+	batch := includeBatch{}
+	scope := ctx.GetScope()
+
+	includes, err := t.figureOutWhichIncludes(scope)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, include := range includes {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		resources, err := t.batchIncluder.IncludeBatch(ctx, t.internalNameOf(include), items, ctx.GetIncludeParams(include))
+		if err != nil {
+			return nil, wrapScopeError(scope.GetIdentifier(include), err)
+		}
+
+		if resources == nil {
+			continue
+		}
+
+		if len(resources) != len(items) {
+			return nil, wrapScopeError(scope.GetIdentifier(include), fmt.Errorf(
+				"the batch includer returned %d resources for %d items", len(resources), len(items),
+			))
+		}
+
+		batch[include] = resources
+	}
+
+	return batch, nil
+}
+
+// Figure out which includes we need, by their public names. The includes the principal
+// is not allowed to see are dropped, or an error is returned if any of them is rejected.
+func (t *BaseTransformer) figureOutWhichIncludes(scope *Scope) ([]string, error) {
+	// Copy the default includes, so filtering never touches the transformer itself
+	includes := append([]string{}, t.publicIncludes(t.GetDefaultIncludes())...)
+
+	for _, available := range t.GetAvailableIncludes() {
+		for _, include := range t.publicNamesOf(available) {
+			if containsString(includes, include) {
+				continue
+			}
+
+			// Deprecated aliases are only included if they are requested explicitly
+			if _, deprecated := t.replacementOf(include); deprecated {
+				if containsString(scope.manager.GetRequestedIncludes(), scope.getIncludePath(include)) {
+					includes = append(includes, include)
+				}
+			} else if scope.IsRequested(include) {
+				includes = append(includes, include)
+			}
 		}
 	}
 
 	target := includes[:0]
 
 	for _, include := range includes {
-		if !scope.IsExcluded(include) {
-			target = append(target, include)
+		// Includes missing from the requested fieldset would be filtered out anyway
+		if scope.IsExcluded(include) || !scope.isFieldRequested(include) {
+			continue
+		}
+
+		if authorizer := scope.manager.includeAuthorizer; authorizer != nil {
+			path := scope.getIncludePath(include)
+
+			switch authorizer(scope, path) {
+			case AuthorizationDrop:
+				continue
+			case AuthorizationReject:
+				// Only the includes requested by name fail the render, the default
+				// includes and the ones requested by a wildcard are dropped instead.
+				if !containsString(scope.manager.GetRequestedIncludes(), path) {
+					continue
+				}
+				return nil, &ForbiddenError{Path: path}
+			}
+		}
+
+		target = append(target, include)
+
+		if replacement, deprecated := t.replacementOf(include); deprecated {
+			scope.deprecateInclude(include, replacement)
 		}
 	}
 
-	return target
+	return target, nil
 }
 
 // Item create a new item resource object.
@@ -151,3 +385,44 @@ func (t *BaseTransformer) Primitive(opts ...ModResourceOption) *Primitive {
 func (t *BaseTransformer) PrimitiveCollection(opts ...ModResourceOption) *PrimitiveCollection {
 	return NewPrimitiveCollection(opts...)
 }
+
+func containsString(list []string, str string) bool {
+	for _, s := range list {
+		if s == str {
+			return true
+		}
+	}
+	return false
+}
+
+// Turn the includer into a ContextIncluder, IncludeWithContext is preferred
+// to IncludeWithError, which is preferred to Include.
+func toContextIncluder(includer Any) ContextIncluder {
+	switch i := includer.(type) {
+	case ContextIncluder:
+		return i
+	case ErrorIncluder:
+		return &errorIncluderAdapter{i}
+	case Includer:
+		return &includerAdapter{i}
+	}
+	return nil
+}
+
+// includerAdapter turns an Includer into a ContextIncluder which never fails
+type includerAdapter struct {
+	Includer
+}
+
+func (i *includerAdapter) IncludeWithContext(ctx *TransformContext, includeName string, data Any, params P) (Resource, error) {
+	return i.Include(includeName, data, params), nil
+}
+
+// errorIncluderAdapter turns an ErrorIncluder into a ContextIncluder
+type errorIncluderAdapter struct {
+	ErrorIncluder
+}
+
+func (i *errorIncluderAdapter) IncludeWithContext(ctx *TransformContext, includeName string, data Any, params P) (Resource, error) {
+	return i.IncludeWithError(includeName, data, params)
+}
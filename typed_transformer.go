@@ -0,0 +1,94 @@
+package fractal
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// TransformerFunc is a type-safe transform closure for data of type T
+type TransformerFunc[T any] func(t *BaseTransformer, data T) M
+
+// TransformerOf is a Transformer which transforms data of type T only
+type TransformerOf[T any] interface {
+	Transformer
+	TransformOf(data T) (M, error)
+}
+
+// TypedTransformer is a transformer for data of type T,
+// data of any other type fails the transformation.
+// It is a ContextTransformer, so it can be shared by concurrent renders.
+type TypedTransformer[T any] struct {
+	*BaseTransformer
+	trans TransformerFunc[T]
+}
+
+// Transform perform transform
+func (t *TypedTransformer[T]) Transform(data Any) M {
+	m, _ := t.TransformWithError(data)
+	return m
+}
+
+// TransformWithError perform transform, fails if the data is not of type T
+func (t *TypedTransformer[T]) TransformWithError(data Any) (M, error) {
+	d, err := t.typed(data)
+	if err != nil {
+		return nil, err
+	}
+
+	return t.TransformOf(d)
+}
+
+// TransformWithContext perform transform, the closure gets a copy of the transformer
+// set to the scope of the context, so a typed transformer can be shared.
+func (t *TypedTransformer[T]) TransformWithContext(ctx *TransformContext, data Any) (M, error) {
+	d, err := t.typed(data)
+	if err != nil {
+		return nil, err
+	}
+
+	return t.trans(t.withScope(ctx), d), nil
+}
+
+// Convert the data to type T, fails if the data is not of type T
+func (t *TypedTransformer[T]) typed(data Any) (T, error) {
+	var d T
+
+	if data != nil {
+		var ok bool
+		if d, ok = data.(T); !ok {
+			return d, fmt.Errorf(
+				"the transformer expects data of type %s but got %T",
+				reflect.TypeOf((*T)(nil)).Elem(), data,
+			)
+		}
+	}
+
+	return d, nil
+}
+
+// TransformOf perform transform for the typed data
+func (t *TypedTransformer[T]) TransformOf(data T) (M, error) {
+	return t.trans(t.BaseTransformer, data), nil
+}
+
+// TOf is a wrapper for type-safe closure transformer
+func TOf[T any](trans TransformerFunc[T]) *TypedTransformer[T] {
+	return &TypedTransformer[T]{
+		BaseTransformer: &BaseTransformer{}, trans: trans,
+	}
+}
+
+// NewItemOf create new item resource, the transformer is checked
+// at compile time to be able to transform the data.
+func NewItemOf[T any, TR TransformerOf[T]](data T, transformer TR, opts ...ModResourceOption) *Item {
+	opts = append(opts, WithData(data), WithTransformer(transformer))
+	return NewItem(opts...)
+}
+
+// NewCollectionOf create new collection resource, the transformer is checked
+// at compile time to be able to transform the items of the data.
+// The typed slice is used as it is instead of being copied into a []Any.
+func NewCollectionOf[T any, TR TransformerOf[T]](data []T, transformer TR, opts ...ModResourceOption) *Collection {
+	opts = append(opts, WithData(data), WithTransformer(transformer))
+	return NewCollection(opts...)
+}
@@ -0,0 +1,81 @@
+package fractal_test
+
+import (
+	"testing"
+
+	"github.com/ibllex/go-fractal"
+	"github.com/stretchr/testify/assert"
+)
+
+func NewTypedBookTransformer() *fractal.TypedTransformer[*Book] {
+	return fractal.TOf(func(t *fractal.BaseTransformer, b *Book) fractal.M {
+		return fractal.M{
+			"id":    b.ID,
+			"title": b.Title,
+		}
+	})
+}
+
+func TestTypedTransformer(t *testing.T) {
+	books := []*Book{
+		{1, "Hogfather", 1998, "Philip K Dick", &Category{}},
+		{2, "Game Of Kill Everyone", 2014, "George R. R. Satan", &Category{}},
+	}
+
+	manager := fractal.NewManager(nil)
+
+	t.Run("item", func(t *testing.T) {
+		resource := fractal.NewItemOf(books[0], NewTypedBookTransformer())
+
+		actual, err := manager.CreateData(resource, nil).ToMap()
+
+		assert.Nil(t, err)
+		assert.Equal(t, fractal.M{"data": fractal.M{"id": 1, "title": "Hogfather"}}, actual)
+	})
+
+	t.Run("collection", func(t *testing.T) {
+		resource := fractal.NewCollectionOf(books, NewTypedBookTransformer())
+
+		expected := fractal.M{"data": []fractal.Any{
+			fractal.M{"id": 1, "title": "Hogfather"},
+			fractal.M{"id": 2, "title": "Game Of Kill Everyone"},
+		}}
+
+		actual, err := manager.CreateData(resource, nil).ToMap()
+
+		assert.Nil(t, err)
+		assert.Equal(t, expected, actual)
+	})
+
+	t.Run("include", func(t *testing.T) {
+		cat := &Category{ID: 1, Name: "novel", Creator: &User{ID: 1, Name: "Tamas"}}
+		transformer := fractal.TOf(func(t *fractal.BaseTransformer, c *Category) fractal.M {
+			return fractal.M{"name": c.Name}
+		})
+		transformer.SetIncluder(&CategoryTransformer{}).SetAvailableIncludes([]string{"creator"})
+
+		manager.ParseIncludes([]string{"creator"})
+		resource := fractal.NewItemOf(cat, transformer)
+
+		expected := fractal.M{"data": fractal.M{
+			"name":    "novel",
+			"creator": fractal.M{"data": fractal.M{"id": 1, "name": "Tamas"}},
+		}}
+
+		actual, err := manager.CreateData(resource, nil).ToMap()
+
+		assert.Nil(t, err)
+		assert.Equal(t, expected, actual)
+	})
+
+	t.Run("mismatched data", func(t *testing.T) {
+		resource := fractal.NewItem(
+			fractal.WithData(Book{}),
+			fractal.WithTransformer(NewTypedBookTransformer()),
+		)
+
+		_, err := manager.CreateData(resource, nil).ToMap()
+
+		assert.EqualError(t, err, "the transformer expects data of type *fractal_test.Book but got fractal_test.Book")
+	})
+}
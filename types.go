@@ -18,6 +18,12 @@ type Cursor interface {
 	GetCount() uint
 }
 
+// LinkedCursor is a cursor which can generate the URL of a cursor position
+type LinkedCursor interface {
+	Cursor
+	GetURL(cursor string) string
+}
+
 // Resource interface
 type Resource interface {
 	GetResourceKey() string
@@ -32,6 +38,13 @@ type Resource interface {
 	SetMetaValue(key string, value Any) Resource
 }
 
+// LinkedResource is implemented by resources which carry links of their own
+type LinkedResource interface {
+	GetLinks() Links
+	SetLinks(links Links) Resource
+	AddLink(rel, href string, attributes M) Resource
+}
+
 // Transformer interface
 type Transformer interface {
 	Transform(data Any) M
@@ -41,7 +54,20 @@ type Transformer interface {
 	SetDefaultIncludes(includes []string) Transformer
 	GetCurrentScope() *Scope
 	SetCurrentScope(scope *Scope) Transformer
-	ProcessIncludedResources(scope *Scope, data Any) M
+	ProcessIncludedResources(ctx *TransformContext, data Any) (M, error)
+}
+
+// ErrorTransformer is implemented by transformers whose transformation may fail,
+// TransformWithError is called instead of Transform if it is implemented.
+type ErrorTransformer interface {
+	TransformWithError(data Any) (M, error)
+}
+
+// ContextTransformer is implemented by transformers which get the scope from the
+// transform context instead of their current scope, TransformWithContext is called
+// instead of Transform and the current scope of the transformer is never set.
+type ContextTransformer interface {
+	TransformWithContext(ctx *TransformContext, data Any) (M, error)
 }
 
 // Includer interface
@@ -49,11 +75,37 @@ type Includer interface {
 	Include(includeName string, data Any, params P) Resource
 }
 
+// ErrorIncluder is implemented by includers whose includes may fail
+type ErrorIncluder interface {
+	IncludeWithError(includeName string, data Any, params P) (Resource, error)
+}
+
+// BatchIncluder is implemented by includers which include the resources of all the items
+// of a collection at once, e.g. by a single query instead of a query per item.
+type BatchIncluder interface {
+	// IncludeBatch include the resources of the items, which are returned in the order
+	// of the items and nil stands for no resource. If nil is returned instead of the
+	// resources, the resources are included item by item as usual.
+	IncludeBatch(ctx *TransformContext, includeName string, items []Any, params P) ([]Resource, error)
+}
+
+// ContextIncluder is implemented by includers which get the scope from the transform context
+type ContextIncluder interface {
+	IncludeWithContext(ctx *TransformContext, includeName string, data Any, params P) (Resource, error)
+}
+
+// Linker is implemented by transformers which contribute the links of every transformed item
+type Linker interface {
+	Links(data Any) Links
+}
+
 // Serializer interface
 type Serializer interface {
 	Collection(resourceKey string, data Any) M
 	Item(resourceKey string, data Any) M
 	Null() M
+	// IncludeData serialize the included data, data is a []M holding the
+	// included data of every item of the resource.
 	IncludeData(resource Resource, data Any) Any
 	Meta(meta M) M
 	Paginator(paginator Paginator) M
@@ -65,6 +117,27 @@ type Serializer interface {
 	FilterIncludes(included, data Any) Any
 }
 
+// FieldsetSerializer is implemented by serializers which keep some fields
+// no matter which sparse fieldset is requested, e.g. the "id" of JSON:API.
+type FieldsetSerializer interface {
+	MandatoryFields() []string
+}
+
+// LinkSerializer is implemented by serializers which render links, the links
+// of resources and of items are dropped by serializers which do not implement it.
+type LinkSerializer interface {
+	// Links serialize the links of the resource
+	Links(links Links) M
+	// InjectLinks inject the links contributed by the transformer into the transformed item
+	InjectLinks(data M, links Links) M
+}
+
+// EmbedSerializer is implemented by serializers which reshape the serialized
+// data of an included resource before it is merged into its parent.
+type EmbedSerializer interface {
+	EmbedData(resource Resource, data M) Any
+}
+
 // ScopeFactory interface
 type ScopeFactory interface {
 	CreateScopeFor(manager *Manager, resource Resource, opts ...ModScopeOption) *Scope